@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day01"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day02"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day03"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day04"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day05"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day06"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day07"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day08"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day09"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day10"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day11"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day12"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day13"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day14"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day15"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day16"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day17"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day18"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day19"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day20"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day21"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day22"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day23"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day24"
+	"github.com/shnako/advent-of-code-2018-ai/solutions/day25"
+)
+
+// solver runs both parts of a day on the given input.
+type solver func(input string) (part1, part2 any, err error)
+
+// visualizer writes a picture of a day's input to w.
+type visualizer func(input string, w io.Writer) error
+
+var solvers = map[int]solver{
+	1:  func(in string) (any, any, error) { s := day01.New(in); return both(s.Part1, s.Part2) },
+	2:  func(in string) (any, any, error) { s := day02.New(in); return both(s.Part1, s.Part2) },
+	3:  func(in string) (any, any, error) { s := day03.New(in); return both(s.Part1, s.Part2) },
+	4:  func(in string) (any, any, error) { s := day04.New(in); return both(s.Part1, s.Part2) },
+	5:  func(in string) (any, any, error) { s := day05.New(in); return both(s.Part1, s.Part2) },
+	6:  func(in string) (any, any, error) { s := day06.New(in); return both(s.Part1, s.Part2) },
+	7:  func(in string) (any, any, error) { s := day07.New(in); return both(s.Part1, s.Part2) },
+	8:  func(in string) (any, any, error) { s := day08.New(in); return both(s.Part1, s.Part2) },
+	9:  func(in string) (any, any, error) { s := day09.New(in); return both(s.Part1, s.Part2) },
+	10: func(in string) (any, any, error) { s := day10.New(in); return both(s.Part1, s.Part2) },
+	11: func(in string) (any, any, error) {
+		s, err := day11.New(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return both(s.Part1, s.Part2)
+	},
+	12: func(in string) (any, any, error) {
+		s, err := day12.New(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return both(s.Part1, s.Part2)
+	},
+	13: func(in string) (any, any, error) { s := day13.New(in); return both(s.Part1, s.Part2) },
+	14: func(in string) (any, any, error) { s := day14.New(in); return both(s.Part1, s.Part2) },
+	15: func(in string) (any, any, error) { s := day15.New(in); return both(s.Part1, s.Part2) },
+	16: func(in string) (any, any, error) { s := day16.New(in); return both(s.Part1, s.Part2) },
+	17: func(in string) (any, any, error) { s := day17.New(in); return both(s.Part1, s.Part2) },
+	18: func(in string) (any, any, error) { s := day18.New(in); return both(s.Part1, s.Part2) },
+	19: func(in string) (any, any, error) { s := day19.New(in); return both(s.Part1, s.Part2) },
+	20: func(in string) (any, any, error) { s := day20.New(in); return both(s.Part1, s.Part2) },
+	21: func(in string) (any, any, error) { s := day21.New(in); return both(s.Part1, s.Part2) },
+	22: func(in string) (any, any, error) { s := day22.New(in); return both(s.Part1, s.Part2) },
+	23: func(in string) (any, any, error) {
+		return both(func() (string, error) { return day23.Part1(in) }, func() (string, error) { return day23.Part2(in) })
+	},
+	24: func(in string) (any, any, error) { return day24.Part1(in), day24.Part2(in), nil },
+	25: func(in string) (any, any, error) { return day25.Part1(in), day25.Part2(in), nil },
+}
+
+var visualizers = map[int]visualizer{
+	3: func(in string, w io.Writer) error {
+		return day03.New(in).WriteHeatmapPNG(w, day03.HeatmapOptions{HighlightIntact: true})
+	},
+	6: func(in string, w io.Writer) error {
+		return day06.New(in).WritePNG(w, day06.ImageOptions{SafeDistance: 10000})
+	},
+	10: func(in string, w io.Writer) error {
+		return day10.New(in).WriteGIF(w, day10.GIFOptions{})
+	},
+}
+
+// both runs two parts, stopping at the first error.
+func both[A, B any](part1 func() (A, error), part2 func() (B, error)) (any, any, error) {
+	a, err := part1()
+	if err != nil {
+		return nil, nil, fmt.Errorf("part 1: %w", err)
+	}
+	b, err := part2()
+	if err != nil {
+		return nil, nil, fmt.Errorf("part 2: %w", err)
+	}
+	return a, b, nil
+}
+
+func main() {
+	day := flag.Int("day", 0, "Day to run (1-25)")
+	inputPath := flag.String("input", "", "Input file (default solutions/dayXX/input.txt)")
+	visualize := flag.String("visualize", "", "Write a visualisation of the input to this file instead of solving (days 3, 6 and 10)")
+	flag.Parse()
+
+	if *day < 1 || *day > 25 {
+		fmt.Fprintf(os.Stderr, "Day must be between 1 and 25\n")
+		os.Exit(1)
+	}
+
+	if *inputPath == "" {
+		*inputPath = filepath.Join("solutions", fmt.Sprintf("day%02d", *day), "input.txt")
+	}
+	content, err := os.ReadFile(*inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+		os.Exit(1)
+	}
+	input := string(content)
+
+	if *visualize != "" {
+		visualizeDay, ok := visualizers[*day]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Day %d has no visualisation\n", *day)
+			os.Exit(1)
+		}
+		if err := writeFile(*visualize, func(w io.Writer) error { return visualizeDay(input, w) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write visualisation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s\n", *visualize)
+		return
+	}
+
+	part1, part2, err := solvers[*day](input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Day %d failed: %v\n", *day, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Part 1: %s\n", format(part1))
+	fmt.Printf("Part 2: %s\n", format(part2))
+}
+
+// format prints multi-line answers starting on their own line.
+func format(answer any) string {
+	text := fmt.Sprint(answer)
+	if strings.Contains(text, "\n") {
+		return "\n" + text
+	}
+	return text
+}
+
+// writeFile creates path and fills it with write, removing it again on failure.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,300 @@
+package ocr
+
+// largeGlyphs are the letters of the 6×10 font.
+var largeGlyphs = map[rune]string{
+	'A': `
+..##..
+.#..#.
+#....#
+#....#
+#....#
+######
+#....#
+#....#
+#....#
+#....#`,
+	'B': `
+#####.
+#....#
+#....#
+#....#
+#####.
+#....#
+#....#
+#....#
+#....#
+#####.`,
+	'C': `
+.####.
+#....#
+#.....
+#.....
+#.....
+#.....
+#.....
+#.....
+#....#
+.####.`,
+	'E': `
+######
+#.....
+#.....
+#.....
+#####.
+#.....
+#.....
+#.....
+#.....
+######`,
+	'F': `
+######
+#.....
+#.....
+#.....
+#####.
+#.....
+#.....
+#.....
+#.....
+#.....`,
+	'G': `
+.####.
+#....#
+#.....
+#.....
+#.....
+#..###
+#....#
+#....#
+#...##
+.###.#`,
+	'H': `
+#....#
+#....#
+#....#
+#....#
+######
+#....#
+#....#
+#....#
+#....#
+#....#`,
+	'J': `
+...###
+....#.
+....#.
+....#.
+....#.
+....#.
+....#.
+#...#.
+#...#.
+.###..`,
+	'K': `
+#....#
+#...#.
+#..#..
+#.#...
+##....
+##....
+#.#...
+#..#..
+#...#.
+#....#`,
+	'L': `
+#.....
+#.....
+#.....
+#.....
+#.....
+#.....
+#.....
+#.....
+#.....
+######`,
+	'N': `
+#....#
+##...#
+##...#
+#.#..#
+#.#..#
+#..#.#
+#..#.#
+#...##
+#...##
+#....#`,
+	'P': `
+#####.
+#....#
+#....#
+#....#
+#####.
+#.....
+#.....
+#.....
+#.....
+#.....`,
+	'R': `
+#####.
+#....#
+#....#
+#....#
+#####.
+#..#..
+#...#.
+#...#.
+#....#
+#....#`,
+	'X': `
+#....#
+#....#
+.#..#.
+.#..#.
+..##..
+..##..
+.#..#.
+.#..#.
+#....#
+#....#`,
+	'Z': `
+######
+.....#
+.....#
+....#.
+...#..
+..#...
+.#....
+#.....
+#.....
+######`,
+}
+
+// smallGlyphs are the letters of the 4×6 font.
+var smallGlyphs = map[rune]string{
+	'A': `
+.##.
+#..#
+#..#
+####
+#..#
+#..#`,
+	'B': `
+###.
+#..#
+###.
+#..#
+#..#
+###.`,
+	'C': `
+.##.
+#..#
+#...
+#...
+#..#
+.##.`,
+	'E': `
+####
+#...
+###.
+#...
+#...
+####`,
+	'F': `
+####
+#...
+###.
+#...
+#...
+#...`,
+	'G': `
+.##.
+#..#
+#...
+#.##
+#..#
+.###`,
+	'H': `
+#..#
+#..#
+####
+#..#
+#..#
+#..#`,
+	'I': `
+###
+.#.
+.#.
+.#.
+.#.
+###`,
+	'J': `
+..##
+...#
+...#
+...#
+#..#
+.##.`,
+	'K': `
+#..#
+#.#.
+##..
+#.#.
+#.#.
+#..#`,
+	'L': `
+#...
+#...
+#...
+#...
+#...
+####`,
+	'O': `
+.##.
+#..#
+#..#
+#..#
+#..#
+.##.`,
+	'P': `
+###.
+#..#
+#..#
+###.
+#...
+#...`,
+	'R': `
+###.
+#..#
+#..#
+###.
+#.#.
+#..#`,
+	'S': `
+.###
+#...
+#...
+.##.
+...#
+###.`,
+	'U': `
+#..#
+#..#
+#..#
+#..#
+#..#
+.##.`,
+	'Y': `
+#...#
+#...#
+.#.#.
+..#..
+..#..
+..#..`,
+	'Z': `
+####
+...#
+..#.
+.#..
+#...
+####`,
+}
@@ -0,0 +1,189 @@
+// Package ocr reads the block-letter messages that Advent of Code puzzles draw
+// with '#' characters, in both the 6×10 and the 4×6 font.
+package ocr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Font is a block-letter font whose glyphs are all the same height.
+type Font struct {
+	Height int
+	glyphs map[string]rune // trimmed glyph rows joined by newlines -> letter
+	widths []int           // distinct glyph widths, widest first
+}
+
+// Large is the 6×10 font, as drawn by 2018 day 10.
+var Large = newFont(10, largeGlyphs)
+
+// Small is the 4×6 font used by most other years. As in the puzzles, 'I' is only
+// 3 wide and 'Y' is 5 wide; 'Y' fills its whole cell, so it touches the next letter.
+var Small = newFont(6, smallGlyphs)
+
+// UnknownGlyphError reports glyphs that match no letter of the font.
+type UnknownGlyphError struct {
+	Text    string // the decoded text with '?' in place of each unknown glyph
+	Columns []int  // the column where each unknown glyph starts, counted from the picture's left edge
+}
+
+func (e *UnknownGlyphError) Error() string {
+	columns := make([]string, len(e.Columns))
+	for i, column := range e.Columns {
+		columns[i] = fmt.Sprint(column)
+	}
+	return fmt.Sprintf("unrecognised glyphs at columns %s (read %q)", strings.Join(columns, ", "), e.Text)
+}
+
+// Decode reads the letters in picture, choosing the font from the height of the
+// drawing. '#' marks lit cells and any other character is dark; blank rows and
+// columns around the drawing are ignored.
+func Decode(picture string) (string, error) {
+	rows, left := trim(strings.Split(strings.ReplaceAll(picture, "\r\n", "\n"), "\n"))
+	for _, font := range []*Font{Large, Small} {
+		if len(rows) == font.Height {
+			return font.decode(rows, left)
+		}
+	}
+	return "", fmt.Errorf("no font is %d rows tall", len(rows))
+}
+
+// Decode reads the letters in picture using this font.
+func (f *Font) Decode(picture string) (string, error) {
+	rows, left := trim(strings.Split(strings.ReplaceAll(picture, "\r\n", "\n"), "\n"))
+	if len(rows) != f.Height {
+		return "", fmt.Errorf("picture is %d rows tall, font is %d", len(rows), f.Height)
+	}
+	return f.decode(rows, left)
+}
+
+// decode splits the trimmed rows into runs of lit columns and reads the glyphs in each.
+// offset is the number of columns trimmed from the left, so errors report positions
+// in the original picture.
+func (f *Font) decode(rows []string, offset int) (string, error) {
+	width := len(rows[0])
+
+	var text strings.Builder
+	var unknown []int
+	for start := 0; start < width; {
+		if columnBlank(rows, start) {
+			start++
+			continue
+		}
+		end := start
+		for end < width && !columnBlank(rows, end) {
+			end++
+		}
+
+		// A run is usually one glyph, but letters with no gap between them share one
+		for start < end {
+			width, letter := f.match(rows, start, end)
+			if width == 0 {
+				text.WriteByte('?')
+				unknown = append(unknown, start+offset)
+				break
+			}
+			text.WriteRune(letter)
+			start += width
+		}
+		start = end
+	}
+
+	if len(unknown) > 0 {
+		return text.String(), &UnknownGlyphError{Text: text.String(), Columns: unknown}
+	}
+	return text.String(), nil
+}
+
+// match finds the glyph that starts the run of lit columns start..end-1, trying the
+// whole run first and then each glyph width, widest first. It returns a width of 0
+// when nothing matches.
+func (f *Font) match(rows []string, start, end int) (int, rune) {
+	lookup := func(width int) (rune, bool) {
+		glyph := make([]string, len(rows))
+		for i, row := range rows {
+			glyph[i] = row[start : start+width]
+		}
+		letter, ok := f.glyphs[strings.Join(glyph, "\n")]
+		return letter, ok
+	}
+	if letter, ok := lookup(end - start); ok {
+		return end - start, letter
+	}
+	for _, width := range f.widths {
+		if width < end-start {
+			if letter, ok := lookup(width); ok {
+				return width, letter
+			}
+		}
+	}
+	return 0, 0
+}
+
+// trim normalises rows to '#' and '.', pads them to the same width, and drops the
+// blank rows and columns around the drawing. It also returns how many leading
+// columns were dropped.
+func trim(rows []string) ([]string, int) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var lit []string
+	top, bottom := -1, -1
+	for _, row := range rows {
+		cells := []byte(strings.Repeat(".", width))
+		for i := 0; i < len(row); i++ {
+			if row[i] == '#' {
+				cells[i] = '#'
+			}
+		}
+		if strings.Contains(string(cells), "#") {
+			if top < 0 {
+				top = len(lit)
+			}
+			bottom = len(lit) + 1
+		}
+		lit = append(lit, string(cells))
+	}
+	if top < 0 {
+		return nil, 0
+	}
+	lit = lit[top:bottom]
+
+	left, right := width, 0
+	for _, row := range lit {
+		left = min(left, strings.Index(row, "#"))
+		right = max(right, strings.LastIndex(row, "#")+1)
+	}
+	for i, row := range lit {
+		lit[i] = row[left:right]
+	}
+	return lit, left
+}
+
+func columnBlank(rows []string, column int) bool {
+	for _, row := range rows {
+		if row[column] == '#' {
+			return false
+		}
+	}
+	return true
+}
+
+func newFont(height int, glyphs map[rune]string) *Font {
+	f := &Font{Height: height, glyphs: make(map[string]rune)}
+	for letter, drawing := range glyphs {
+		rows, _ := trim(strings.Split(strings.TrimSpace(drawing), "\n"))
+		if len(rows) != height {
+			panic(fmt.Sprintf("ocr: glyph %q is %d rows tall, want %d", letter, len(rows), height))
+		}
+		f.glyphs[strings.Join(rows, "\n")] = letter
+		if !slices.Contains(f.widths, len(rows[0])) {
+			f.widths = append(f.widths, len(rows[0]))
+		}
+	}
+	slices.Sort(f.widths)
+	slices.Reverse(f.widths)
+	return f
+}
@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// smallSample is "HIYZ" laid out as the puzzles do, one letter per 5-column cell,
+// so the Y touches the Z.
+const smallSample = `
+#..#..###.#...#####.
+#..#...#..#...#...#.
+####...#...#.#...#..
+#..#...#....#...#...
+#..#...#....#..#....
+#..#..###...#..####.`
+
+// largeSample is "AJZ" in 2018 day 10's font, one letter per 8-column cell.
+const largeSample = `
+..##.......###..######..
+.#..#.......#........#..
+#....#......#........#..
+#....#......#.......#...
+#....#......#......#....
+######......#.....#.....
+#....#......#....#......
+#....#..#...#...#.......
+#....#..#...#...#.......
+#....#...###....######..`
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		picture string
+		font    *Font
+		want    string
+	}{
+		{"small font", smallSample, Small, "HIYZ"},
+		{"large font", largeSample, Large, "AJZ"},
+		{"dark cells as spaces", strings.ReplaceAll(smallSample, ".", " "), Small, "HIYZ"},
+		{"windows line endings", strings.ReplaceAll(largeSample, "\n", "\r\n"), Large, "AJZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Decode(tt.picture); err != nil || got != tt.want {
+				t.Errorf("Decode() = %q, %v, want %q", got, err, tt.want)
+			}
+			if got, err := tt.font.Decode(tt.picture); err != nil || got != tt.want {
+				t.Errorf("Font.Decode() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// lightCell returns picture with the cell at row, column lit, counting rows after the leading newline.
+func lightCell(picture string, row, column int) string {
+	rows := strings.Split(strings.TrimPrefix(picture, "\n"), "\n")
+	rows[row] = rows[row][:column] + "#" + rows[row][column+1:]
+	return strings.Join(rows, "\n")
+}
+
+func TestUnknownGlyphColumns(t *testing.T) {
+	// Two blank columns in front, so positions count from the picture's edge, not the drawing's
+	padded := strings.ReplaceAll(smallSample, "\n", "\n..")
+
+	tests := []struct {
+		name    string
+		picture string
+		text    string
+		columns []int
+	}{
+		{"inside a separate letter", lightCell(padded, 2, 8), "H?YZ", []int{8}},
+		{"after a letter it touches", lightCell(padded, 3, 19), "HIY?", []int{17}},
+		{"two letters", lightCell(lightCell(padded, 2, 8), 1, 3), "??YZ", []int{2, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := Decode(tt.picture)
+			var unknown *UnknownGlyphError
+			if !errors.As(err, &unknown) {
+				t.Fatalf("Decode() error = %v, want an UnknownGlyphError", err)
+			}
+			if text != tt.text || unknown.Text != tt.text || !slices.Equal(unknown.Columns, tt.columns) {
+				t.Errorf("Decode() = %q, error text %q, columns %v; want %q, columns %v",
+					text, unknown.Text, unknown.Columns, tt.text, tt.columns)
+			}
+		})
+	}
+}
+
+func TestDecodeHeightErrors(t *testing.T) {
+	if _, err := Decode("#\n#\n#"); err == nil {
+		t.Errorf("Decode() of a 3-row picture succeeded, want an error")
+	}
+	if _, err := Large.Decode(smallSample); err == nil {
+		t.Errorf("Large.Decode() of a 6-row picture succeeded, want an error")
+	}
+	if _, err := Small.Decode(largeSample); err == nil {
+		t.Errorf("Small.Decode() of a 10-row picture succeeded, want an error")
+	}
+}
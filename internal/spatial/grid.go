@@ -0,0 +1,77 @@
+package spatial
+
+// Grid buckets points into uniform cubic cells for fixed-radius neighbour queries.
+// Queries only look at the cells a radius can reach, so with a cell size close to
+// the query radius each lookup touches a constant number of cells instead of every point.
+type Grid struct {
+	dims     int
+	cellSize int
+	points   []Point
+	cells    map[Point][]int
+}
+
+// NewGrid indexes points using their first dims coordinates and cells of side cellSize.
+func NewGrid(points []Point, dims, cellSize int) *Grid {
+	if cellSize < 1 {
+		cellSize = 1
+	}
+	g := &Grid{
+		dims:     dims,
+		cellSize: cellSize,
+		points:   points,
+		cells:    make(map[Point][]int),
+	}
+	for i, p := range points {
+		cell := g.cellOf(p)
+		g.cells[cell] = append(g.cells[cell], i)
+	}
+	return g
+}
+
+func (g *Grid) cellOf(p Point) Point {
+	var cell Point
+	for d := 0; d < g.dims; d++ {
+		cell[d] = floorDiv(p[d], g.cellSize)
+	}
+	return cell
+}
+
+// WithinRadius returns the indices of all points within Manhattan distance r of center,
+// in no particular order.
+func (g *Grid) WithinRadius(center Point, r int) []int {
+	var result []int
+	g.VisitWithinRadius(center, r, func(i int) {
+		result = append(result, i)
+	})
+	return result
+}
+
+// VisitWithinRadius calls visit with the index of every point within Manhattan distance r of center.
+func (g *Grid) VisitWithinRadius(center Point, r int, visit func(int)) {
+	lo := g.cellOf(center)
+	hi := lo
+	for d := 0; d < g.dims; d++ {
+		lo[d] = floorDiv(center[d]-r, g.cellSize)
+		hi[d] = floorDiv(center[d]+r, g.cellSize)
+	}
+
+	// Walk every cell in the bounding cube of the query, odometer style
+	cell := lo
+	for {
+		for _, i := range g.cells[cell] {
+			if Manhattan(center, g.points[i]) <= r {
+				visit(i)
+			}
+		}
+
+		d := 0
+		for d < g.dims && cell[d] == hi[d] {
+			cell[d] = lo[d]
+			d++
+		}
+		if d == g.dims {
+			return
+		}
+		cell[d]++
+	}
+}
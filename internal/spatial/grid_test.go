@@ -0,0 +1,45 @@
+package spatial
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestGridWithinRadiusMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	for _, dims := range []int{1, 2, 4} {
+		points := make([]Point, 300)
+		for i := range points {
+			points[i] = randomPoint(rng, dims, 12)
+		}
+
+		// Cells smaller than, equal to and larger than the radius, and the clamped 0
+		for _, cellSize := range []int{0, 1, 3, 7} {
+			grid := NewGrid(points, dims, cellSize)
+			for query := 0; query < 50; query++ {
+				center, r := randomPoint(rng, dims, 15), rng.Intn(8)
+
+				var want []int
+				for i, p := range points {
+					if Manhattan(center, p) <= r {
+						want = append(want, i)
+					}
+				}
+
+				var visited []int
+				grid.VisitWithinRadius(center, r, func(i int) { visited = append(visited, i) })
+				slices.Sort(visited)
+				if !slices.Equal(visited, want) {
+					t.Errorf("dims %d, cell %d: VisitWithinRadius(%v, %d) = %v, want %v", dims, cellSize, center, r, visited, want)
+				}
+
+				got := grid.WithinRadius(center, r)
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Errorf("dims %d, cell %d: WithinRadius(%v, %d) = %v, want %v", dims, cellSize, center, r, got, want)
+				}
+			}
+		}
+	}
+}
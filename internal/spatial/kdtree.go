@@ -0,0 +1,169 @@
+package spatial
+
+import "sort"
+
+// Ball is an L1 ball: every point within Manhattan distance Radius of Center.
+type Ball struct {
+	Center Point
+	Radius int
+}
+
+// leafSize is the most balls a k-d tree node holds before it is split.
+const leafSize = 8
+
+// BallTree is a k-d tree over L1 ball centers. Each node keeps the bounding box
+// of its centers and the range of its radii, so whole subtrees can be counted
+// or skipped at once when every (or no) ball in them can reach a query box.
+type BallTree struct {
+	dims  int
+	balls []Ball
+	root  *ballNode
+}
+
+type ballNode struct {
+	bounds               Box
+	minRadius, maxRadius int
+	count                int
+	left, right          *ballNode
+	items                []int // ball indices, leaves only
+}
+
+// NewBallTree indexes balls using the first dims coordinates of their centers.
+func NewBallTree(balls []Ball, dims int) *BallTree {
+	t := &BallTree{dims: dims, balls: balls}
+	if len(balls) == 0 {
+		return t
+	}
+	indices := make([]int, len(balls))
+	for i := range indices {
+		indices[i] = i
+	}
+	t.root = t.build(indices)
+	return t
+}
+
+func (t *BallTree) build(indices []int) *ballNode {
+	first := t.balls[indices[0]]
+	node := &ballNode{
+		bounds:    Box{Min: first.Center, Max: first.Center},
+		minRadius: first.Radius,
+		maxRadius: first.Radius,
+		count:     len(indices),
+	}
+	for _, i := range indices[1:] {
+		ball := t.balls[i]
+		for d := 0; d < t.dims; d++ {
+			node.bounds.Min[d] = min(node.bounds.Min[d], ball.Center[d])
+			node.bounds.Max[d] = max(node.bounds.Max[d], ball.Center[d])
+		}
+		node.minRadius = min(node.minRadius, ball.Radius)
+		node.maxRadius = max(node.maxRadius, ball.Radius)
+	}
+
+	if len(indices) <= leafSize {
+		node.items = indices
+		return node
+	}
+
+	// Split at the median of the widest dimension
+	axis := 0
+	for d := 1; d < t.dims; d++ {
+		if node.bounds.Max[d]-node.bounds.Min[d] > node.bounds.Max[axis]-node.bounds.Min[axis] {
+			axis = d
+		}
+	}
+	sort.Slice(indices, func(a, b int) bool {
+		return t.balls[indices[a]].Center[axis] < t.balls[indices[b]].Center[axis]
+	})
+	mid := len(indices) / 2
+	node.left = t.build(indices[:mid])
+	node.right = t.build(indices[mid:])
+	return node
+}
+
+// CountIntersecting returns how many balls reach at least one point of box.
+func (t *BallTree) CountIntersecting(box Box) int {
+	nearest := func(bounds Box) int {
+		// Closest any center in bounds can be to the box
+		dist := 0
+		for d := 0; d < t.dims; d++ {
+			dist += max(0, box.Min[d]-bounds.Max[d], bounds.Min[d]-box.Max[d])
+		}
+		return dist
+	}
+	farthest := func(bounds Box) int {
+		// Farthest any center in bounds can be from the box
+		dist := 0
+		for d := 0; d < t.dims; d++ {
+			dist += max(gap(bounds.Min[d], box.Min[d], box.Max[d]), gap(bounds.Max[d], box.Min[d], box.Max[d]))
+		}
+		return dist
+	}
+	reaches := func(ball Ball) bool {
+		return DistanceToBox(ball.Center, box) <= ball.Radius
+	}
+	return t.count(t.root, nearest, farthest, reaches)
+}
+
+// CountContaining returns how many balls contain every point of box.
+func (t *BallTree) CountContaining(box Box) int {
+	// spread is the distance from c to the farther end of lo..hi
+	spread := func(c, lo, hi int) int {
+		return max(abs(c-lo), abs(c-hi))
+	}
+	nearest := func(bounds Box) int {
+		// The best center sits as close to the box's middle as bounds allow
+		dist := 0
+		for d := 0; d < t.dims; d++ {
+			mid := floorDiv(box.Min[d]+box.Max[d], 2)
+			lo := min(max(mid, bounds.Min[d]), bounds.Max[d])
+			hi := min(max(mid+1, bounds.Min[d]), bounds.Max[d])
+			dist += min(spread(lo, box.Min[d], box.Max[d]), spread(hi, box.Min[d], box.Max[d]))
+		}
+		return dist
+	}
+	farthest := func(bounds Box) int {
+		dist := 0
+		for d := 0; d < t.dims; d++ {
+			dist += max(spread(bounds.Min[d], box.Min[d], box.Max[d]), spread(bounds.Max[d], box.Min[d], box.Max[d]))
+		}
+		return dist
+	}
+	contains := func(ball Ball) bool {
+		dist := 0
+		for d := 0; d < t.dims; d++ {
+			dist += spread(ball.Center[d], box.Min[d], box.Max[d])
+		}
+		return dist <= ball.Radius
+	}
+	return t.count(t.root, nearest, farthest, contains)
+}
+
+// CountContainingPoint returns how many balls contain p.
+func (t *BallTree) CountContainingPoint(p Point) int {
+	return t.CountIntersecting(Box{Min: p, Max: p})
+}
+
+// count walks the tree, using nearest and farthest bounds on the distance from a
+// node's centers to skip nodes no ball can satisfy and take nodes every ball satisfies.
+func (t *BallTree) count(node *ballNode, nearest, farthest func(Box) int, match func(Ball) bool) int {
+	if node == nil {
+		return 0
+	}
+	if nearest(node.bounds) > node.maxRadius {
+		return 0
+	}
+	if farthest(node.bounds) <= node.minRadius {
+		return node.count
+	}
+	if node.items != nil {
+		count := 0
+		for _, i := range node.items {
+			if match(t.balls[i]) {
+				count++
+			}
+		}
+		return count
+	}
+	return t.count(node.left, nearest, farthest, match) + t.count(node.right, nearest, farthest, match)
+}
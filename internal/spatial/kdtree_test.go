@@ -0,0 +1,76 @@
+package spatial
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomPoint(rng *rand.Rand, dims, spread int) Point {
+	var p Point
+	for d := 0; d < dims; d++ {
+		p[d] = rng.Intn(2*spread+1) - spread
+	}
+	return p
+}
+
+// containsBox checks every corner of box, which is enough for a convex ball.
+func containsBox(ball Ball, box Box, dims int) bool {
+	for corner := 0; corner < 1<<dims; corner++ {
+		var p Point
+		for d := 0; d < dims; d++ {
+			if corner&(1<<d) != 0 {
+				p[d] = box.Max[d]
+			} else {
+				p[d] = box.Min[d]
+			}
+		}
+		if Manhattan(ball.Center, p) > ball.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBallTreeCountsMatchBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(32))
+	for _, dims := range []int{1, 2, 3} {
+		for _, n := range []int{0, 1, 7, 100} {
+			balls := make([]Ball, n)
+			for i := range balls {
+				balls[i] = Ball{Center: randomPoint(rng, dims, 30), Radius: rng.Intn(40)}
+			}
+			tree := NewBallTree(balls, dims)
+
+			for query := 0; query < 200; query++ {
+				a, b := randomPoint(rng, dims, 40), randomPoint(rng, dims, 40)
+				var box Box
+				for d := 0; d < dims; d++ {
+					box.Min[d], box.Max[d] = min(a[d], b[d]), max(a[d], b[d])
+				}
+
+				wantIntersecting, wantContaining, wantPoint := 0, 0, 0
+				for _, ball := range balls {
+					if DistanceToBox(ball.Center, box) <= ball.Radius {
+						wantIntersecting++
+					}
+					if containsBox(ball, box, dims) {
+						wantContaining++
+					}
+					if Manhattan(ball.Center, a) <= ball.Radius {
+						wantPoint++
+					}
+				}
+
+				if got := tree.CountIntersecting(box); got != wantIntersecting {
+					t.Errorf("dims %d, %d balls: CountIntersecting(%v) = %d, want %d", dims, n, box, got, wantIntersecting)
+				}
+				if got := tree.CountContaining(box); got != wantContaining {
+					t.Errorf("dims %d, %d balls: CountContaining(%v) = %d, want %d", dims, n, box, got, wantContaining)
+				}
+				if got := tree.CountContainingPoint(a); got != wantPoint {
+					t.Errorf("dims %d, %d balls: CountContainingPoint(%v) = %d, want %d", dims, n, a, got, wantPoint)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package spatial provides spatial indexes for Manhattan-distance queries in two to four
+// dimensions and sweep-line overlap queries on axis-aligned rectangles.
+package spatial
+
+// Point is a coordinate in up to four dimensions; unused dimensions stay zero.
+type Point [4]int
+
+// Box is an axis-aligned box with inclusive bounds.
+type Box struct {
+	Min, Max Point
+}
+
+// Manhattan returns the Manhattan (L1) distance between two points.
+func Manhattan(a, b Point) int {
+	dist := 0
+	for d := range a {
+		dist += abs(a[d] - b[d])
+	}
+	return dist
+}
+
+// DistanceToBox returns the Manhattan distance from p to the nearest point of box,
+// or 0 if p lies inside it.
+func DistanceToBox(p Point, box Box) int {
+	dist := 0
+	for d := range p {
+		dist += gap(p[d], box.Min[d], box.Max[d])
+	}
+	return dist
+}
+
+// gap returns how far v lies outside the range lo..hi.
+func gap(v, lo, hi int) int {
+	if v < lo {
+		return lo - v
+	}
+	if v > hi {
+		return v - hi
+	}
+	return 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// floorDiv divides rounding towards negative infinity, so cells tile negative coordinates evenly.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,197 @@
+package spatial
+
+import "sort"
+
+// Rect is an axis-aligned rectangle of unit cells with half-open bounds:
+// it covers every cell (x, y) with MinX <= x < MaxX and MinY <= y < MaxY.
+type Rect struct {
+	MinX, MinY int
+	MaxX, MaxY int
+}
+
+// Area returns the number of cells r covers.
+func (r Rect) Area() int {
+	return max(0, r.MaxX-r.MinX) * max(0, r.MaxY-r.MinY)
+}
+
+// Overlaps reports whether r and o share at least one cell.
+func (r Rect) Overlaps(o Rect) bool {
+	return r.MinX < o.MaxX && o.MinX < r.MaxX && r.MinY < o.MaxY && o.MinY < r.MaxY
+}
+
+// RectSet answers overlap questions about a fixed set of rectangles by sorting
+// their edges rather than painting cells, so the cost depends on the number of
+// rectangles rather than on how large the coordinates are.
+type RectSet struct {
+	rects    []Rect
+	overlaps [][]int // overlaps[i] lists the rectangles sharing a cell with rects[i]
+}
+
+// NewRectSet indexes rects and finds every overlapping pair.
+// Rectangles are sorted by MinX and each is compared with those starting before
+// it ends; overlap in y is checked pair by pair. That is fast for scattered
+// rectangles but O(n²) when many share an x range, such as a column of thin strips.
+func NewRectSet(rects []Rect) *RectSet {
+	s := &RectSet{rects: rects, overlaps: make([][]int, len(rects))}
+
+	// Sweep left to right: each rectangle only needs checking against the ones
+	// that start before it ends
+	order := make([]int, 0, len(rects))
+	for i, r := range rects {
+		if r.Area() > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return rects[order[a]].MinX < rects[order[b]].MinX
+	})
+	for a, i := range order {
+		for _, j := range order[a+1:] {
+			if rects[j].MinX >= rects[i].MaxX {
+				break
+			}
+			if rects[i].Overlaps(rects[j]) {
+				s.overlaps[i] = append(s.overlaps[i], j)
+				s.overlaps[j] = append(s.overlaps[j], i)
+			}
+		}
+	}
+	for _, list := range s.overlaps {
+		sort.Ints(list)
+	}
+	return s
+}
+
+// Overlapping returns the indices of the rectangles sharing a cell with rectangle i, in ascending order.
+func (s *RectSet) Overlapping(i int) []int {
+	return s.overlaps[i]
+}
+
+// Isolated returns the indices of the rectangles that overlap no other, in ascending order.
+// Rectangles with zero area cover no cells, so they are always included.
+func (s *RectSet) Isolated() []int {
+	var result []int
+	for i, list := range s.overlaps {
+		if len(list) == 0 {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// UnionArea returns the number of cells covered by at least one rectangle.
+func (s *RectSet) UnionArea() int {
+	return s.CoveredArea(1)
+}
+
+// CoveredArea returns the number of cells covered by at least k rectangles.
+func (s *RectSet) CoveredArea(k int) int {
+	if k < 1 {
+		k = 1
+	}
+
+	type event struct {
+		x, minY, maxY, delta int
+	}
+	var events []event
+	var ys []int
+	for _, r := range s.rects {
+		if r.Area() == 0 {
+			continue
+		}
+		events = append(events, event{r.MinX, r.MinY, r.MaxY, 1}, event{r.MaxX, r.MinY, r.MaxY, -1})
+		ys = append(ys, r.MinY, r.MaxY)
+	}
+	if len(events) == 0 {
+		return 0
+	}
+	sort.Slice(events, func(a, b int) bool { return events[a].x < events[b].x })
+
+	// Compress the y edges into elementary intervals
+	sort.Ints(ys)
+	unique := ys[:1]
+	for _, y := range ys[1:] {
+		if y != unique[len(unique)-1] {
+			unique = append(unique, y)
+		}
+	}
+	ys = unique
+	tree := newCoverTree(ys, k)
+	index := func(y int) int {
+		return sort.SearchInts(ys, y)
+	}
+
+	// Sweep left to right, adding the covered height of each vertical slab
+	area := 0
+	for i, e := range events {
+		if i > 0 {
+			area += tree.covered() * (e.x - events[i-1].x)
+		}
+		tree.add(index(e.minY), index(e.maxY), e.delta)
+	}
+	return area
+}
+
+// coverTree is a segment tree over the elementary intervals between sorted y edges.
+// Each node tracks how many whole rectangles span it and how much of it is
+// covered at least 1..k times.
+type coverTree struct {
+	ys    []int
+	k     int
+	cover []int
+	at    []int // at[node*k+j-1] is the length of node covered at least j times
+}
+
+func newCoverTree(ys []int, k int) *coverTree {
+	n := max(1, len(ys)-1)
+	return &coverTree{
+		ys:    ys,
+		k:     k,
+		cover: make([]int, 4*n),
+		at:    make([]int, 4*n*k),
+	}
+}
+
+// covered returns the total length covered at least k times.
+func (t *coverTree) covered() int {
+	return t.at[t.k+t.k-1] // the root is node 1
+}
+
+// add adds delta to the cover count of the intervals lo..hi-1.
+func (t *coverTree) add(lo, hi, delta int) {
+	if lo < hi {
+		t.update(1, 0, len(t.ys)-1, lo, hi, delta)
+	}
+}
+
+func (t *coverTree) update(node, nodeLo, nodeHi, lo, hi, delta int) {
+	if hi <= nodeLo || nodeHi <= lo {
+		return
+	}
+	if lo <= nodeLo && nodeHi <= hi {
+		t.cover[node] += delta
+	} else {
+		mid := (nodeLo + nodeHi) / 2
+		t.update(2*node, nodeLo, mid, lo, hi, delta)
+		t.update(2*node+1, mid, nodeHi, lo, hi, delta)
+	}
+	t.pull(node, nodeLo, nodeHi)
+}
+
+// pull recomputes a node's coverage from its own count and its children.
+func (t *coverTree) pull(node, nodeLo, nodeHi int) {
+	length := t.ys[nodeHi] - t.ys[nodeLo]
+	leaf := nodeHi-nodeLo == 1
+	c := t.cover[node]
+	for j := 1; j <= t.k; j++ {
+		switch {
+		case c >= j:
+			t.at[node*t.k+j-1] = length
+		case leaf:
+			t.at[node*t.k+j-1] = 0
+		default:
+			need := j - c
+			t.at[node*t.k+j-1] = t.at[2*node*t.k+need-1] + t.at[(2*node+1)*t.k+need-1]
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package utils
+
+// ReadingOrderDirs lists the four cardinal directions in reading order: up, left, right, down.
+// Expanding neighbors in this order keeps breadth-first searches deterministic.
+var ReadingOrderDirs = []Point{North, West, East, South}
+
+// ReadingLess reports whether a comes before b in reading order
+// (top-to-bottom, then left-to-right).
+func ReadingLess(a, b Point) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
+
+// DistanceField holds the result of a breadth-first search over a grid.
+// Dist and Parent cover every point reachable from the start points.
+// First records, for each reached point, the step taken out of a start point
+// on a shortest path to it, choosing the reading-order-first step on ties.
+type DistanceField struct {
+	Dist   map[Point]int
+	Parent map[Point]Point
+	First  map[Point]Point
+}
+
+// BFSField runs a breadth-first search from the start points, expanding
+// neighbors in reading order, and returns the full distance field.
+// isOpen reports whether a point may be entered; start points are always included.
+func BFSField(starts []Point, isOpen func(Point) bool) *DistanceField {
+	field := &DistanceField{
+		Dist:   make(map[Point]int),
+		Parent: make(map[Point]Point),
+		First:  make(map[Point]Point),
+	}
+
+	queue := make([]Point, 0, len(starts))
+	for _, start := range starts {
+		if _, seen := field.Dist[start]; !seen {
+			field.Dist[start] = 0
+			queue = append(queue, start)
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		currentDist := field.Dist[current]
+
+		for _, dir := range ReadingOrderDirs {
+			next := current.Add(dir)
+
+			// A start point's neighbors are their own first step
+			step, hasStep := field.First[current]
+			if !hasStep {
+				step = next
+			}
+
+			if d, seen := field.Dist[next]; seen {
+				// Another shortest path may reach this point through an earlier first step
+				if d == currentDist+1 && ReadingLess(step, field.First[next]) {
+					field.First[next] = step
+				}
+				continue
+			}
+
+			if !isOpen(next) {
+				continue
+			}
+
+			field.Dist[next] = currentDist + 1
+			field.Parent[next] = current
+			field.First[next] = step
+			queue = append(queue, next)
+		}
+	}
+
+	return field
+}
+
+// Distance returns the BFS distance to p and whether p was reached.
+func (f *DistanceField) Distance(p Point) (int, bool) {
+	d, ok := f.Dist[p]
+	return d, ok
+}
+
+// Nearest returns the reachable target with the smallest distance,
+// breaking ties in reading order, along with that distance.
+func (f *DistanceField) Nearest(targets []Point) (Point, int, bool) {
+	var best Point
+	bestDist := -1
+	for _, target := range targets {
+		d, ok := f.Dist[target]
+		if !ok {
+			continue
+		}
+		if bestDist < 0 || d < bestDist || (d == bestDist && ReadingLess(target, best)) {
+			best, bestDist = target, d
+		}
+	}
+	return best, bestDist, bestDist >= 0
+}
+
+// FirstStep returns the reading-order-first step out of a start point
+// along a shortest path to target. It is false if target is a start point or unreached.
+func (f *DistanceField) FirstStep(target Point) (Point, bool) {
+	step, ok := f.First[target]
+	return step, ok
+}
+
+// StepToward picks the nearest reachable target (ties broken in reading order)
+// and returns the reading-order-first step towards it along with the chosen target.
+// It is false if no target is reachable or the nearest target is a start point.
+func (f *DistanceField) StepToward(targets []Point) (step, target Point, ok bool) {
+	target, _, found := f.Nearest(targets)
+	if !found {
+		return Point{}, Point{}, false
+	}
+	step, ok = f.FirstStep(target)
+	return step, target, ok
+}
+
+// PathTo reconstructs a shortest path from a start point to p.
+// Returns nil if p was not reached.
+func (f *DistanceField) PathTo(p Point) []Point {
+	if _, ok := f.Dist[p]; !ok {
+		return nil
+	}
+	path := []Point{p}
+	for {
+		prev, ok := f.Parent[p]
+		if !ok {
+			break
+		}
+		path = append(path, prev)
+		p = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// openCells returns an isOpen function for a map drawn with '#' for walls.
+func openCells(drawing string) func(Point) bool {
+	rows := strings.Split(strings.TrimSpace(drawing), "\n")
+	return func(p Point) bool {
+		return p.Y >= 0 && p.Y < len(rows) && p.X >= 0 && p.X < len(rows[p.Y]) && rows[p.Y][p.X] != '#'
+	}
+}
+
+func TestBFSFieldFirstStepTies(t *testing.T) {
+	tests := []struct {
+		name     string
+		drawing  string
+		starts   []Point
+		target   Point
+		wantDist int
+		wantStep Point
+	}{
+		{
+			// Round the pillar both ways is 4 steps; going right first comes before going down
+			name: "around a pillar",
+			drawing: `
+...
+.#.
+...`,
+			starts:   []Point{{X: 0, Y: 0}},
+			target:   Point{X: 2, Y: 2},
+			wantDist: 4,
+			wantStep: Point{X: 1, Y: 0},
+		},
+		{
+			// Left and right corridors both reach the bottom in 5; left is first in reading order
+			name: "two corridors",
+			drawing: `
+#...#
+#.#.#
+#.#.#
+#...#`,
+			starts:   []Point{{X: 2, Y: 0}},
+			target:   Point{X: 2, Y: 3},
+			wantDist: 5,
+			wantStep: Point{X: 1, Y: 0},
+		},
+		{
+			// Both starts are 2 away; the later start's step is first in reading order,
+			// so it has to replace the step recorded when the target was first reached
+			name: "later start wins",
+			drawing: `
+...
+...
+...`,
+			starts:   []Point{{X: 2, Y: 2}, {X: 0, Y: 0}},
+			target:   Point{X: 1, Y: 1},
+			wantDist: 2,
+			wantStep: Point{X: 1, Y: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := BFSField(tt.starts, openCells(tt.drawing))
+			if d, ok := field.Distance(tt.target); !ok || d != tt.wantDist {
+				t.Errorf("Distance(%v) = %d, %v, want %d", tt.target, d, ok, tt.wantDist)
+			}
+			if step, ok := field.FirstStep(tt.target); !ok || step != tt.wantStep {
+				t.Errorf("FirstStep(%v) = %v, %v, want %v", tt.target, step, ok, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestStepToward(t *testing.T) {
+	// (3,3) and (4,2) are both 3 steps from (2,1); (4,2) comes first in reading order,
+	// and of the two equally short first steps towards it, right comes before down
+	drawing := `
+#######
+#.E...#
+#.....#
+#...G.#
+#######`
+	isOpen := openCells(drawing)
+	field := BFSField([]Point{{X: 2, Y: 1}}, isOpen)
+
+	targets := []Point{{X: 3, Y: 3}, {X: 4, Y: 2}, {X: 5, Y: 3}}
+	step, target, ok := field.StepToward(targets)
+	if !ok || target != (Point{X: 4, Y: 2}) || step != (Point{X: 3, Y: 1}) {
+		t.Errorf("StepToward() = %v, %v, %v, want (3,1) towards (4,2)", step, target, ok)
+	}
+
+	// A start point is its own nearest target but has no step
+	if _, _, ok := field.StepToward([]Point{{X: 2, Y: 1}}); ok {
+		t.Errorf("StepToward(start) ok = true, want false")
+	}
+	if _, _, ok := field.StepToward([]Point{{X: 0, Y: 0}}); ok {
+		t.Errorf("StepToward(wall) ok = true, want false")
+	}
+}
@@ -31,4 +31,53 @@ func (cd *CycleDetector[T]) Add(state T) (bool, int, int) {
 func (cd *CycleDetector[T]) Reset() {
 	cd.seen = make(map[T]int)
 	cd.index = 0
-}
\ No newline at end of file
+}
+
+// AdvanceTo returns the state after applying step n times to initial.
+// Each state is hashed with key; as soon as a key repeats, whole cycles are
+// skipped and only the remaining steps are simulated. step may mutate and
+// return its argument, since earlier states are never revisited.
+func AdvanceTo[T any, K comparable](initial T, n int, step func(T) T, key func(T) K) T {
+	detector := NewCycleDetector[K]()
+	state := initial
+	for i := 0; i < n; i++ {
+		if found, _, cycleLen := detector.Add(key(state)); found {
+			for remaining := (n - i) % cycleLen; remaining > 0; remaining-- {
+				state = step(state)
+			}
+			return state
+		}
+		state = step(state)
+	}
+	return state
+}
+
+// ExtrapolateMetric returns metric of the state after applying step n times to initial.
+// Each state is hashed with key; once a key repeats with period L, the metric at each
+// position in the cycle is assumed to change by the same amount every L steps. That
+// amount is zero for plain cycles and non-zero for drifting ones, e.g. a pattern that
+// keeps its shape while shifting along a line, when key ignores the offset and metric
+// is linear in it. Like AdvanceTo, step may mutate and return its argument.
+func ExtrapolateMetric[T any, K comparable](initial T, n int, step func(T) T, key func(T) K, metric func(T) int) int {
+	detector := NewCycleDetector[K]()
+	metrics := []int{}
+	state := initial
+	for i := 0; ; i++ {
+		value := metric(state)
+		if found, cycleStart, cycleLen := detector.Add(key(state)); found {
+			// Step on to the position in the cycle that n lands on, one period later than
+			// its first visit, to measure how much that position drifts per period
+			cycles, offset := (n-cycleStart)/cycleLen, (n-cycleStart)%cycleLen
+			for j := 0; j < offset; j++ {
+				state = step(state)
+			}
+			drift := metric(state) - metrics[cycleStart+offset]
+			return metrics[cycleStart+offset] + cycles*drift
+		}
+		if i == n {
+			return value
+		}
+		metrics = append(metrics, value)
+		state = step(state)
+	}
+}
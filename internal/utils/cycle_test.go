@@ -0,0 +1,82 @@
+package utils
+
+import "testing"
+
+// The sequences below use the step count itself as the state, so the expected
+// value for any n can be computed directly and compared with the shortcut.
+
+// tailThenCycle keys step counts 0, 1, 2 uniquely, then repeats keys with period 4.
+func tailThenCycle(g int) int {
+	if g < 3 {
+		return g
+	}
+	return 3 + (g-3)%4
+}
+
+func increment(g int) int { return g + 1 }
+
+func TestAdvanceTo(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		key  func(int) int
+	}{
+		{"before the cycle starts", 2, tailThenCycle},
+		{"first state of the cycle", 3, tailThenCycle},
+		{"exactly one period in", 7, tailThenCycle},
+		{"mid cycle", 1_000_000_005, tailThenCycle},
+		{"cycle of length 1", 1_000_000_000, func(g int) int { return min(g, 5) }},
+		{"no steps", 0, tailThenCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// States equal to n mod the key's period are interchangeable, so compare keys
+			steps := 0
+			got := AdvanceTo(0, tt.n, func(g int) int { steps++; return increment(g) }, tt.key)
+			if tt.key(got) != tt.key(tt.n) {
+				t.Errorf("AdvanceTo(0, %d) = %d with key %d, want key %d", tt.n, got, tt.key(got), tt.key(tt.n))
+			}
+			if steps > 20 {
+				t.Errorf("AdvanceTo(0, %d) stepped %d times, want the cycle skipped", tt.n, steps)
+			}
+		})
+	}
+}
+
+func TestExtrapolateMetric(t *testing.T) {
+	// In the drifting sequence each of the four positions in the cycle moves by a
+	// different amount per period, so using one position's drift for all is wrong
+	drifting := func(g int) int {
+		if g < 3 {
+			return 100 * g
+		}
+		phase, cycles := (g-3)%4, (g-3)/4
+		return 7*phase + cycles*(phase+1)
+	}
+	stable := func(g int) int { return 3 * min(g, 5) }
+
+	tests := []struct {
+		name   string
+		n      int
+		key    func(int) int
+		metric func(int) int
+	}{
+		{"before the cycle starts", 2, tailThenCycle, drifting},
+		{"first state of the cycle", 3, tailThenCycle, drifting},
+		{"exactly one period in", 7, tailThenCycle, drifting},
+		{"one period and a bit", 9, tailThenCycle, drifting},
+		{"far away, each position", 1_000_000_000, tailThenCycle, drifting},
+		{"far away, another position", 1_000_000_002, tailThenCycle, drifting},
+		{"cycle of length 1 without drift", 1_000_000_000, func(g int) int { return min(g, 5) }, stable},
+		{"cycle of length 1 with drift", 1_000_000_000, func(g int) int { return min(g, 5) }, func(g int) int { return 2*g + 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := ExtrapolateMetric(0, tt.n, increment, tt.key, tt.metric), tt.metric(tt.n); got != want {
+				t.Errorf("ExtrapolateMetric(0, %d) = %d, want %d", tt.n, got, want)
+			}
+		})
+	}
+}
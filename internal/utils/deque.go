@@ -0,0 +1,129 @@
+package utils
+
+// Deque is a double-ended queue backed by a growable ring buffer.
+// Pushing and popping at either end is O(1) amortised, and Rotate moves
+// elements between the ends without allocating, which makes it a compact
+// replacement for circular linked lists.
+type Deque[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+// NewDeque creates an empty deque with room for at least capacity elements.
+func NewDeque[T any](capacity int) *Deque[T] {
+	c := 1
+	for c < capacity {
+		c <<= 1
+	}
+	return &Deque[T]{buf: make([]T, c)}
+}
+
+// Len returns the number of elements in the deque.
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+// index maps a position from the front to a slot in the buffer.
+// The buffer length is always a power of two, so wrapping is a mask.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) & (len(d.buf) - 1)
+}
+
+func (d *Deque[T]) grow() {
+	if d.size < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(1, 2*len(d.buf)))
+	for i := 0; i < d.size; i++ {
+		buf[i] = d.buf[d.index(i)]
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// PushBack adds a value at the back.
+func (d *Deque[T]) PushBack(value T) {
+	d.grow()
+	d.buf[d.index(d.size)] = value
+	d.size++
+}
+
+// PushFront adds a value at the front.
+func (d *Deque[T]) PushFront(value T) {
+	d.grow()
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = value
+	d.size++
+}
+
+// PopBack removes and returns the value at the back.
+// The deque must not be empty.
+func (d *Deque[T]) PopBack() T {
+	i := d.index(d.size - 1)
+	value := d.buf[i]
+	var zero T
+	d.buf[i] = zero
+	d.size--
+	return value
+}
+
+// PopFront removes and returns the value at the front.
+// The deque must not be empty.
+func (d *Deque[T]) PopFront() T {
+	value := d.buf[d.head]
+	var zero T
+	d.buf[d.head] = zero
+	d.head = d.index(1)
+	d.size--
+	return value
+}
+
+// Front returns the value at the front. The deque must not be empty.
+func (d *Deque[T]) Front() T {
+	return d.buf[d.head]
+}
+
+// Back returns the value at the back. The deque must not be empty.
+func (d *Deque[T]) Back() T {
+	return d.buf[d.index(d.size-1)]
+}
+
+// At returns the i-th value counting from the front.
+func (d *Deque[T]) At(i int) T {
+	return d.buf[d.index(i)]
+}
+
+// Rotate treats the deque as a circle and moves n elements from the front to
+// the back, or -n elements from the back to the front when n is negative.
+// A full buffer rotates in O(1) by moving the head alone; otherwise each element
+// moves one at a time, the shorter way round, so the cost is O(min(|n|, Len()-|n|)).
+func (d *Deque[T]) Rotate(n int) {
+	if d.size <= 1 {
+		return
+	}
+	n %= d.size
+	if d.size == len(d.buf) {
+		d.head = d.index(n + d.size)
+		return
+	}
+	if n > d.size/2 {
+		n -= d.size
+	} else if n < -d.size/2 {
+		n += d.size
+	}
+
+	// Clear each vacated slot, as the pops do, so the buffer holds no stale references
+	var zero T
+	for ; n > 0; n-- {
+		d.buf[d.index(d.size)] = d.buf[d.head]
+		d.buf[d.head] = zero
+		d.head = d.index(1)
+	}
+	for ; n < 0; n++ {
+		d.head = d.index(len(d.buf) - 1)
+		back := d.index(d.size)
+		d.buf[d.head] = d.buf[back]
+		d.buf[back] = zero
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// contents lists the deque from front to back.
+func contents[T any](d *Deque[T]) []T {
+	values := make([]T, d.Len())
+	for i := range values {
+		values[i] = d.At(i)
+	}
+	return values
+}
+
+func TestDequeMatchesSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(35))
+	d := NewDeque[int](2)
+	var want []int
+
+	for op := 0; op < 5000; op++ {
+		switch r := rng.Intn(6); {
+		case r == 0:
+			d.PushBack(op)
+			want = append(want, op)
+		case r == 1:
+			d.PushFront(op)
+			want = append([]int{op}, want...)
+		case r == 2 && len(want) > 0:
+			if got := d.PopBack(); got != want[len(want)-1] {
+				t.Fatalf("op %d: PopBack() = %d, want %d", op, got, want[len(want)-1])
+			}
+			want = want[:len(want)-1]
+		case r == 3 && len(want) > 0:
+			if got := d.PopFront(); got != want[0] {
+				t.Fatalf("op %d: PopFront() = %d, want %d", op, got, want[0])
+			}
+			want = want[1:]
+		default:
+			n := rng.Intn(41) - 20
+			d.Rotate(n)
+			if len(want) > 0 {
+				k := ((n % len(want)) + len(want)) % len(want)
+				want = append(want[k:len(want):len(want)], want[:k]...)
+			}
+		}
+		if got := contents(d); !slices.Equal(got, want) {
+			t.Fatalf("op %d: deque = %v, want %v", op, got, want)
+		}
+	}
+}
+
+func TestDequeRotateClearsVacatedSlots(t *testing.T) {
+	d := NewDeque[*int](8)
+	for i := 0; i < 5; i++ {
+		d.PushBack(new(int))
+	}
+
+	for _, n := range []int{1, 2, -1, -3, 4, 7, -9} {
+		d.Rotate(n)
+
+		// Slots outside the live range must not keep pointers reachable
+		live := 0
+		for _, p := range d.buf {
+			if p != nil {
+				live++
+			}
+		}
+		if live != d.Len() {
+			t.Errorf("after Rotate(%d) the buffer holds %d pointers for %d elements", n, live, d.Len())
+		}
+	}
+}
@@ -1,46 +1,5 @@
 package utils
 
-import "container/heap"
-
-// Item represents a priority queue item with a value, priority, and index.
-type Item struct {
-	Value    interface{}
-	Priority int
-	Index    int
-}
-
-// PriorityQueue implements heap.Interface for priority-based algorithms.
-type PriorityQueue []*Item
-
-func (pq PriorityQueue) Len() int { return len(pq) }
-
-func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].Priority < pq[j].Priority
-}
-
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].Index = i
-	pq[j].Index = j
-}
-
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*Item)
-	item.Index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.Index = -1
-	*pq = old[0 : n-1]
-	return item
-}
-
 // BFS performs breadth-first search from start point to find target.
 // Returns the path and whether a path was found.
 func BFS(start Point, isTarget func(Point) bool, getNeighbors func(Point) []Point) ([]Point, bool) {
@@ -103,58 +62,130 @@ func DFS(start Point, isTarget func(Point) bool, getNeighbors func(Point) []Poin
 	return dfsHelper(start)
 }
 
-// Dijkstra finds the shortest path using Dijkstra's algorithm.
-// Returns the cost and path to the target, or -1 and nil if no path exists.
-func Dijkstra(start Point, isTarget func(Point) bool, getNeighbors func(Point) []PointWithCost) (int, []Point) {
-	pq := make(PriorityQueue, 0)
-	heap.Init(&pq)
+// Edge represents a weighted transition to another search state.
+type Edge[S comparable] struct {
+	To   S
+	Cost int
+}
 
-	dist := make(map[Point]int)
-	parent := make(map[Point]Point)
+// SearchResult holds the outcome of a weighted search.
+// Dist and Parent cover every state settled or discovered during the search.
+type SearchResult[S comparable] struct {
+	Dist   map[S]int
+	Parent map[S]S
+	Target S
+	Found  bool
+}
 
-	dist[start] = 0
-	heap.Push(&pq, &Item{Value: start, Priority: 0})
+// Cost returns the cost of the reached target, or -1 if none was found.
+func (r *SearchResult[S]) Cost() int {
+	if !r.Found {
+		return -1
+	}
+	return r.Dist[r.Target]
+}
 
-	for pq.Len() > 0 {
-		item := heap.Pop(&pq).(*Item)
-		current := item.Value.(Point)
-		currentDist := item.Priority
+// PathTo reconstructs the path from a start state to the given state.
+// Returns nil if the state was never reached.
+func (r *SearchResult[S]) PathTo(state S) []S {
+	if _, ok := r.Dist[state]; !ok {
+		return nil
+	}
+	path := []S{state}
+	for {
+		prev, ok := r.Parent[state]
+		if !ok {
+			break
+		}
+		path = append(path, prev)
+		state = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
 
-		if isTarget(current) {
-			// Reconstruct path
-			path := []Point{}
-			for p := current; p != start; p = parent[p] {
-				path = append([]Point{p}, path...)
-			}
-			path = append([]Point{start}, path...)
-			return currentDist, path
+// Path returns the path to the reached target, or nil if none was found.
+func (r *SearchResult[S]) Path() []S {
+	if !r.Found {
+		return nil
+	}
+	return r.PathTo(r.Target)
+}
+
+// Dijkstra finds the cheapest path from any of the start states using Dijkstra's algorithm.
+// The search stops at the first state satisfying isTarget; pass a nil isTarget
+// to explore the whole reachable graph and get the full distance map.
+func Dijkstra[S comparable](starts []S, isTarget func(S) bool, getNeighbors func(S) []Edge[S]) *SearchResult[S] {
+	return AStar(starts, isTarget, getNeighbors, nil)
+}
+
+// AStar finds the cheapest path from any of the start states using A* search.
+// The heuristic must never overestimate the remaining cost to a target;
+// a nil heuristic makes this equivalent to Dijkstra.
+func AStar[S comparable](starts []S, isTarget func(S) bool, getNeighbors func(S) []Edge[S], heuristic func(S) int) *SearchResult[S] {
+	result := &SearchResult[S]{
+		Dist:   make(map[S]int),
+		Parent: make(map[S]S),
+	}
+	estimate := func(s S) int {
+		if heuristic == nil {
+			return 0
 		}
+		return heuristic(s)
+	}
 
-		if d, ok := dist[current]; ok && currentDist > d {
+	pq := NewPQ(func(a, b searchItem[S]) bool { return a.priority < b.priority })
+	open := make(map[S]*Handle[searchItem[S]])
+	for _, start := range starts {
+		if _, ok := result.Dist[start]; ok {
 			continue
 		}
+		result.Dist[start] = 0
+		open[start] = pq.Push(searchItem[S]{state: start, cost: 0, priority: estimate(start)})
+	}
 
-		for _, nc := range getNeighbors(current) {
-			newDist := currentDist + nc.Cost
-			if d, ok := dist[nc.Point]; !ok || newDist < d {
-				dist[nc.Point] = newDist
-				parent[nc.Point] = current
-				heap.Push(&pq, &Item{Value: nc.Point, Priority: newDist})
+	for pq.Len() > 0 {
+		item := pq.Pop()
+		current := item.state
+		delete(open, current)
+
+		if isTarget != nil && isTarget(current) {
+			result.Target = current
+			result.Found = true
+			return result
+		}
+
+		for _, edge := range getNeighbors(current) {
+			newDist := item.cost + edge.Cost
+			if d, ok := result.Dist[edge.To]; ok && newDist >= d {
+				continue
+			}
+			result.Dist[edge.To] = newDist
+			result.Parent[edge.To] = current
+			next := searchItem[S]{state: edge.To, cost: newDist, priority: newDist + estimate(edge.To)}
+			if h, queued := open[edge.To]; queued {
+				pq.Update(h, next)
+			} else {
+				open[edge.To] = pq.Push(next)
 			}
 		}
 	}
 
-	return -1, nil
+	return result
 }
 
-// PointWithCost represents a point with an associated movement cost.
-type PointWithCost struct {
-	Point Point
-	Cost  int
+// searchItem is a queued state in a weighted search.
+type searchItem[S comparable] struct {
+	state    S
+	cost     int
+	priority int
 }
 
 // TopologicalSort performs topological sorting on a directed acyclic graph.
 // Returns the sorted nodes and whether the graph is acyclic.
+// Ties are broken by the order of nodes; use ScheduleTasks for a custom tie-break order.
 func TopologicalSort(nodes []string, edges map[string][]string) ([]string, bool) {
 	inDegree := make(map[string]int)
 	for _, node := range nodes {
@@ -167,9 +198,10 @@ func TopologicalSort(nodes []string, edges map[string][]string) ([]string, bool)
 		}
 	}
 
+	// Seed the queue in the given node order so the result is deterministic
 	queue := []string{}
-	for node, degree := range inDegree {
-		if degree == 0 {
+	for _, node := range nodes {
+		if inDegree[node] == 0 {
 			queue = append(queue, node)
 		}
 	}
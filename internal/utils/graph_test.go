@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// smallGraph is a weighted directed graph where the cheapest way from a to d
+// is the longer path a-b-c-d, and no edge leads to e.
+var smallGraph = map[string][]Edge[string]{
+	"a": {{To: "b", Cost: 1}, {To: "d", Cost: 10}},
+	"b": {{To: "c", Cost: 2}},
+	"c": {{To: "d", Cost: 3}},
+	"e": {{To: "a", Cost: 1}},
+}
+
+func smallNeighbors(s string) []Edge[string] {
+	return smallGraph[s]
+}
+
+func TestDijkstraPath(t *testing.T) {
+	result := Dijkstra([]string{"a"}, func(s string) bool { return s == "d" }, smallNeighbors)
+	if !result.Found || result.Target != "d" || result.Cost() != 6 {
+		t.Fatalf("Dijkstra() found %v at %q with cost %d, want d with cost 6", result.Found, result.Target, result.Cost())
+	}
+	if got, want := result.Path(), []string{"a", "b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	if got, want := result.PathTo("c"), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("PathTo(c) = %v, want %v", got, want)
+	}
+	if got := result.PathTo("e"); got != nil {
+		t.Errorf("PathTo(e) = %v, want nil for a state that was never reached", got)
+	}
+}
+
+func TestDijkstraUnreachable(t *testing.T) {
+	result := Dijkstra([]string{"a"}, func(s string) bool { return s == "e" }, smallNeighbors)
+	if result.Found || result.Cost() != -1 || result.Path() != nil {
+		t.Errorf("Dijkstra() = found %v, cost %d, path %v; want no target", result.Found, result.Cost(), result.Path())
+	}
+}
+
+func TestDijkstraFullDistances(t *testing.T) {
+	result := Dijkstra([]string{"a"}, nil, smallNeighbors)
+	if result.Found {
+		t.Errorf("Dijkstra() with nil isTarget reported target %q", result.Target)
+	}
+	want := map[string]int{"a": 0, "b": 1, "c": 3, "d": 6}
+	if !maps.Equal(result.Dist, want) {
+		t.Errorf("Dist = %v, want %v", result.Dist, want)
+	}
+}
+
+func TestDijkstraMultipleStarts(t *testing.T) {
+	// Starting from both b and e, each state is measured from the nearer start
+	result := Dijkstra([]string{"b", "e", "b"}, nil, smallNeighbors)
+	want := map[string]int{"a": 1, "b": 0, "c": 2, "d": 5, "e": 0}
+	if !maps.Equal(result.Dist, want) {
+		t.Errorf("Dist = %v, want %v", result.Dist, want)
+	}
+	if got, want := result.PathTo("d"), []string{"b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("PathTo(d) = %v, want %v", got, want)
+	}
+	if got, want := result.PathTo("a"), []string{"e", "a"}; !slices.Equal(got, want) {
+		t.Errorf("PathTo(a) = %v, want %v", got, want)
+	}
+}
+
+func TestAStarMatchesDijkstra(t *testing.T) {
+	rng := rand.New(rand.NewSource(26))
+	const size = 12
+
+	for trial := 0; trial < 50; trial++ {
+		// Every step costs at least 1, so the Manhattan distance never overestimates
+		cost := make(map[Point]int)
+		for x := 0; x < size; x++ {
+			for y := 0; y < size; y++ {
+				cost[Point{X: x, Y: y}] = 1 + rng.Intn(9)
+			}
+		}
+		neighbors := func(p Point) []Edge[Point] {
+			var edges []Edge[Point]
+			for _, n := range p.Neighbors4() {
+				if c, ok := cost[n]; ok {
+					edges = append(edges, Edge[Point]{To: n, Cost: c})
+				}
+			}
+			return edges
+		}
+		start := Point{X: rng.Intn(size), Y: rng.Intn(size)}
+		target := Point{X: rng.Intn(size), Y: rng.Intn(size)}
+		isTarget := func(p Point) bool { return p == target }
+
+		dijkstra := Dijkstra([]Point{start}, isTarget, neighbors)
+		astar := AStar([]Point{start}, isTarget, neighbors, func(p Point) int { return p.Manhattan(target) })
+		if !astar.Found || astar.Cost() != dijkstra.Cost() {
+			t.Fatalf("%v to %v: AStar() cost = %d, Dijkstra() cost = %d", start, target, astar.Cost(), dijkstra.Cost())
+		}
+
+		// The path must start at the start, end at the target and cost what was reported
+		path := astar.Path()
+		total := 0
+		for i := 1; i < len(path); i++ {
+			if path[i-1].Manhattan(path[i]) != 1 {
+				t.Fatalf("%v to %v: path %v has a non-adjacent step", start, target, path)
+			}
+			total += cost[path[i]]
+		}
+		if path[0] != start || path[len(path)-1] != target || total != astar.Cost() {
+			t.Fatalf("%v to %v: path %v costs %d, want %d", start, target, path, total, astar.Cost())
+		}
+	}
+}
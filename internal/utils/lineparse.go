@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseError reports where a line failed to match a pattern.
+// Line and Column are 1-based; Line is 0 when parsing a single line.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// patternPart is either a literal or a placeholder bound to a struct field.
+type patternPart struct {
+	literal string
+	name    string
+	field   []int
+}
+
+// LineParser fills structs of type T from lines matching a pattern such as
+// "#{id} @ {x},{y}: {w}x{h}". Each {name} placeholder binds to the exported
+// field tagged `parse:"name"`, or else to the field with that name (case-insensitive).
+// Supported field kinds are signed and unsigned integers, floats and strings;
+// numeric values may be padded with spaces.
+type LineParser[T any] struct {
+	pattern string
+	parts   []patternPart
+}
+
+// NewLineParser compiles a pattern for struct type T.
+// Returns an error if a placeholder is empty, has no matching field of a supported kind,
+// shares its field with another placeholder, or directly follows another placeholder.
+func NewLineParser[T any](pattern string) (*LineParser[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("pattern target must be a struct, got %s", typ)
+	}
+
+	var parts []patternPart
+	rest := pattern
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			parts = append(parts, patternPart{literal: rest})
+			break
+		}
+		if open > 0 {
+			parts = append(parts, patternPart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed placeholder in pattern %q", pattern)
+		}
+		name := rest[open+1 : open+end]
+		if name == "" {
+			return nil, fmt.Errorf("empty placeholder {} in pattern %q", pattern)
+		}
+		if len(parts) > 0 && parts[len(parts)-1].literal == "" {
+			return nil, fmt.Errorf("placeholders {%s} and {%s} must be separated by literal text", parts[len(parts)-1].name, name)
+		}
+		field, err := lookupField(typ, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			if part.literal == "" && reflect.DeepEqual(part.field, field) {
+				return nil, fmt.Errorf("placeholders {%s} and {%s} bind the same field", part.name, name)
+			}
+		}
+		parts = append(parts, patternPart{name: name, field: field})
+		rest = rest[open+end+1:]
+	}
+
+	return &LineParser[T]{pattern: pattern, parts: parts}, nil
+}
+
+// lookupField finds the field a placeholder binds to, preferring parse tags over names,
+// and checks that setField can fill it.
+func lookupField(typ reflect.Type, name string) ([]int, error) {
+	var found *reflect.StructField
+	for _, f := range reflect.VisibleFields(typ) {
+		if f.IsExported() && f.Tag.Get("parse") == name {
+			found = &f
+			break
+		}
+	}
+	if found == nil {
+		for _, f := range reflect.VisibleFields(typ) {
+			if f.IsExported() && strings.EqualFold(f.Name, name) {
+				found = &f
+				break
+			}
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no exported field for placeholder {%s} in %s", name, typ)
+	}
+	if !supportedKind(found.Type.Kind()) {
+		return nil, fmt.Errorf("placeholder {%s}: unsupported field type %s", name, found.Type)
+	}
+	return found.Index, nil
+}
+
+// supportedKind reports whether setField can fill a field of this kind.
+func supportedKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Parse matches a single line against the pattern.
+func (p *LineParser[T]) Parse(line string) (T, error) {
+	var result T
+	value := reflect.ValueOf(&result).Elem()
+
+	pos := 0
+	for i, part := range p.parts {
+		if part.literal != "" {
+			if !strings.HasPrefix(line[pos:], part.literal) {
+				return result, &ParseError{Column: pos + 1, Msg: fmt.Sprintf("expected %q", part.literal)}
+			}
+			pos += len(part.literal)
+			continue
+		}
+
+		// A placeholder runs up to the next literal, or to the end of the line
+		end := len(line)
+		if i+1 < len(p.parts) {
+			next := p.parts[i+1].literal
+			idx := strings.Index(line[pos:], next)
+			if idx < 0 {
+				return result, &ParseError{Column: pos + 1, Msg: fmt.Sprintf("expected %q after {%s}", next, part.name)}
+			}
+			end = pos + idx
+		}
+
+		if err := setField(value.FieldByIndex(part.field), line[pos:end]); err != nil {
+			return result, &ParseError{Column: pos + 1, Msg: fmt.Sprintf("{%s}: %v", part.name, err)}
+		}
+		pos = end
+	}
+
+	if pos < len(line) {
+		return result, &ParseError{Column: pos + 1, Msg: fmt.Sprintf("unexpected trailing text %q", line[pos:])}
+	}
+
+	return result, nil
+}
+
+// ParseAll parses every non-empty line of input.
+// Surrounding whitespace on each line is ignored; errors carry the line number.
+func (p *LineParser[T]) ParseAll(input string) ([]T, error) {
+	lines := strings.Split(input, "\n")
+	results := make([]T, 0, len(lines))
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		item, err := p.Parse(line)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Line = i + 1
+				pe.Column += len(raw) - len(strings.TrimLeftFunc(raw, unicode.IsSpace))
+			}
+			return nil, err
+		}
+		results = append(results, item)
+	}
+
+	return results, nil
+}
+
+// ParseLine parses a single line into a T using pattern.
+func ParseLine[T any](line, pattern string) (T, error) {
+	parser, err := NewLineParser[T](pattern)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return parser.Parse(line)
+}
+
+// ParseLines parses every non-empty line of input into a T using pattern.
+func ParseLines[T any](input, pattern string) ([]T, error) {
+	parser, err := NewLineParser[T](pattern)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseAll(input)
+}
+
+// setField converts text into the field's type and stores it.
+func setField(field reflect.Value, text string) error {
+	if field.Kind() == reflect.String {
+		field.SetString(text)
+		return nil
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("missing value")
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", text)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", text)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", text)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
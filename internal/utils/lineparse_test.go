@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type claim struct {
+	ID   int `parse:"id"`
+	X, Y int
+	W    int `parse:"w"`
+	H    int `parse:"h"`
+}
+
+const claimPattern = "#{id} @ {x},{y}: {w}x{h}"
+
+func TestParseLine(t *testing.T) {
+	got, err := ParseLine[claim]("#123 @ 3,2: 5x4", claimPattern)
+	if err != nil {
+		t.Fatalf("ParseLine() error = %v", err)
+	}
+	if want := (claim{ID: 123, X: 3, Y: 2, W: 5, H: 4}); got != want {
+		t.Errorf("ParseLine() = %+v, want %+v", got, want)
+	}
+
+	// Numbers may be padded, as in day 10's "position=< 9,  1>"
+	type point struct{ X, Y int }
+	p, err := ParseLine[point]("<-3,  11>", "<{x},{y}>")
+	if err != nil || p != (point{X: -3, Y: 11}) {
+		t.Errorf("ParseLine() = %+v, %v, want {-3 11}", p, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+		msg    string
+	}{
+		{"malformed field", "#12a @ 3,2: 5x4", 0, 2, `{id}: invalid integer "12a"`},
+		{"missing field", "#1 @ ,2: 5x4", 0, 6, "{x}: missing value"},
+		{"missing leading literal", "123 @ 3,2: 5x4", 0, 1, `expected "#"`},
+		{"missing literal after field", "#1 @ 3;2: 5x4", 0, 6, `expected "," after {x}`},
+		{"trailing input", "#1 @ 3,2: 5x4 extra", 0, 13, `{h}: invalid integer "4 extra"`},
+		{"second line", "#1 @ 3,2: 5x4\n#2 @ 3,x: 5x4", 2, 8, `{y}: invalid integer "x"`},
+		{"after blank and indented lines", "#1 @ 3,2: 5x4\n\n   #3 @ 1,1 2x2", 3, 11, `expected ": " after {y}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if strings.Contains(tt.input, "\n") {
+				_, err = ParseLines[claim](tt.input, claimPattern)
+			} else {
+				_, err = ParseLine[claim](tt.input, claimPattern)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error = %v, want a *ParseError", err)
+			}
+			if pe.Line != tt.line || pe.Column != tt.column || pe.Msg != tt.msg {
+				t.Errorf("error = line %d, column %d, %q; want line %d, column %d, %q",
+					pe.Line, pe.Column, pe.Msg, tt.line, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseTrailingLiteral(t *testing.T) {
+	// Text after the last literal is reported at the column where it starts
+	type pair struct{ A, B int }
+	_, err := ParseLine[pair]("(1, 2) and more", "({a}, {b})")
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Column != 7 || pe.Msg != `unexpected trailing text " and more"` {
+		t.Errorf("ParseLine() error = %v, want column 7: unexpected trailing text", err)
+	}
+}
+
+func TestNewLineParserErrors(t *testing.T) {
+	type fields struct {
+		Name    string
+		Count   int
+		Ratio   float64
+		Tags    []string
+		Flag    bool
+		private int
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"empty placeholder", "{} => {count}", "empty placeholder"},
+		{"unclosed placeholder", "{name} => {count", "unclosed placeholder"},
+		{"unknown field", "{name} => {total}", "no exported field for placeholder {total}"},
+		{"unexported field", "{private}", "no exported field for placeholder {private}"},
+		{"slice field", "{name}: {tags}", "placeholder {tags}: unsupported field type []string"},
+		{"bool field", "{flag}", "placeholder {flag}: unsupported field type bool"},
+		{"adjacent placeholders", "{name}{count}", "must be separated by literal text"},
+		{"same field twice", "{count} of {Count}", "placeholders {count} and {Count} bind the same field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLineParser[fields](tt.pattern)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewLineParser(%q) error = %v, want it to mention %q", tt.pattern, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewLineParser[int]("{x}"); err == nil {
+		t.Errorf("NewLineParser[int]() succeeded, want an error for a non-struct target")
+	}
+	if _, err := NewLineParser[fields]("{name} x{count} ~{ratio}"); err != nil {
+		t.Errorf("NewLineParser() error = %v for a valid pattern", err)
+	}
+}
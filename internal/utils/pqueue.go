@@ -0,0 +1,163 @@
+package utils
+
+// Handle references an element stored in a PQ.
+// It stays valid until the element is popped or removed, and lets callers
+// change the element's priority in place (decrease-key).
+type Handle[T any] struct {
+	Value T
+	index int
+}
+
+// PQ is a binary min-heap ordered by a caller-supplied comparator.
+// The element for which less reports true against all others is popped first.
+type PQ[T any] struct {
+	items []*Handle[T]
+	less  func(a, b T) bool
+}
+
+// NewPQ creates an empty priority queue ordered by less.
+func NewPQ[T any](less func(a, b T) bool) *PQ[T] {
+	return &PQ[T]{less: less}
+}
+
+// Len returns the number of elements in the queue.
+func (pq *PQ[T]) Len() int {
+	return len(pq.items)
+}
+
+// Push adds a value to the queue and returns its handle.
+func (pq *PQ[T]) Push(value T) *Handle[T] {
+	h := &Handle[T]{Value: value, index: len(pq.items)}
+	pq.items = append(pq.items, h)
+	pq.up(h.index)
+	return h
+}
+
+// Peek returns the highest-priority value without removing it.
+// The queue must not be empty.
+func (pq *PQ[T]) Peek() T {
+	return pq.items[0].Value
+}
+
+// Pop removes and returns the highest-priority value.
+// The queue must not be empty.
+func (pq *PQ[T]) Pop() T {
+	return pq.Remove(pq.items[0])
+}
+
+// Update replaces the value behind a handle and restores heap order.
+// Use it to decrease (or increase) an element's priority.
+func (pq *PQ[T]) Update(h *Handle[T], value T) {
+	h.Value = value
+	if !pq.down(h.index) {
+		pq.up(h.index)
+	}
+}
+
+// Remove deletes the element behind a handle and returns its value.
+func (pq *PQ[T]) Remove(h *Handle[T]) T {
+	i := h.index
+	last := len(pq.items) - 1
+	if i != last {
+		pq.swap(i, last)
+	}
+	pq.items[last] = nil
+	pq.items = pq.items[:last]
+	if i != last && !pq.down(i) {
+		pq.up(i)
+	}
+	h.index = -1
+	return h.Value
+}
+
+// Contains reports whether the handle still refers to an element in the queue.
+func (pq *PQ[T]) Contains(h *Handle[T]) bool {
+	return h.index >= 0 && h.index < len(pq.items) && pq.items[h.index] == h
+}
+
+func (pq *PQ[T]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+func (pq *PQ[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !pq.less(pq.items[i].Value, pq.items[parent].Value) {
+			break
+		}
+		pq.swap(i, parent)
+		i = parent
+	}
+}
+
+// down sifts the element at i towards the leaves and reports whether it moved.
+func (pq *PQ[T]) down(i int) bool {
+	start := i
+	n := len(pq.items)
+	for {
+		smallest := i
+		left, right := 2*i+1, 2*i+2
+		if left < n && pq.less(pq.items[left].Value, pq.items[smallest].Value) {
+			smallest = left
+		}
+		if right < n && pq.less(pq.items[right].Value, pq.items[smallest].Value) {
+			smallest = right
+		}
+		if smallest == i {
+			break
+		}
+		pq.swap(i, smallest)
+		i = smallest
+	}
+	return i > start
+}
+
+// BucketQueue is a monotone priority queue for small non-negative integer priorities.
+// Values are kept in one bucket per priority, so Push and Pop are O(1) amortised.
+// It suits Dijkstra-style searches with small edge costs (e.g. 1 to move, 7 to switch),
+// where a pushed priority is never lower than the last popped one.
+type BucketQueue[T any] struct {
+	buckets [][]T
+	current int
+	size    int
+}
+
+// NewBucketQueue creates an empty bucket queue.
+func NewBucketQueue[T any]() *BucketQueue[T] {
+	return &BucketQueue[T]{}
+}
+
+// Len returns the number of elements in the queue.
+func (bq *BucketQueue[T]) Len() int {
+	return bq.size
+}
+
+// Push adds a value with the given priority.
+// Priorities below the last popped priority are clamped up to it.
+func (bq *BucketQueue[T]) Push(value T, priority int) {
+	if priority < bq.current {
+		priority = bq.current
+	}
+	for priority >= len(bq.buckets) {
+		bq.buckets = append(bq.buckets, nil)
+	}
+	bq.buckets[priority] = append(bq.buckets[priority], value)
+	bq.size++
+}
+
+// Pop removes and returns a value with the lowest priority, along with that priority.
+// The queue must not be empty.
+func (bq *BucketQueue[T]) Pop() (T, int) {
+	for len(bq.buckets[bq.current]) == 0 {
+		// Release drained buckets so long searches don't hold on to memory
+		bq.buckets[bq.current] = nil
+		bq.current++
+	}
+	bucket := bq.buckets[bq.current]
+	value := bucket[len(bucket)-1]
+	bq.buckets[bq.current] = bucket[:len(bucket)-1]
+	bq.size--
+	return value, bq.current
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// drain pops every value left in pq.
+func drain(pq *PQ[int]) []int {
+	var values []int
+	for pq.Len() > 0 {
+		values = append(values, pq.Pop())
+	}
+	return values
+}
+
+func TestPQUpdate(t *testing.T) {
+	pq := NewPQ(func(a, b int) bool { return a < b })
+	handles := make(map[int]*Handle[int])
+	for _, v := range []int{50, 20, 40, 10, 30} {
+		handles[v] = pq.Push(v)
+	}
+
+	// Decrease one key past the minimum and increase another past the maximum
+	pq.Update(handles[40], 5)
+	pq.Update(handles[10], 60)
+	if got := pq.Peek(); got != 5 {
+		t.Errorf("Peek() after Update = %d, want 5", got)
+	}
+	if got, want := drain(pq), []int{5, 20, 30, 50, 60}; !slices.Equal(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+}
+
+func TestPQRemove(t *testing.T) {
+	pq := NewPQ(func(a, b int) bool { return a < b })
+	handles := make(map[int]*Handle[int])
+	for _, v := range []int{7, 3, 9, 1, 5, 8} {
+		handles[v] = pq.Push(v)
+	}
+
+	if got := pq.Remove(handles[3]); got != 3 {
+		t.Errorf("Remove() = %d, want 3", got)
+	}
+	if pq.Contains(handles[3]) {
+		t.Errorf("Contains() after Remove = true, want false")
+	}
+	if !pq.Contains(handles[9]) {
+		t.Errorf("Contains() for a queued value = false, want true")
+	}
+
+	// Removing the last slot must not disturb the rest
+	pq.Remove(handles[8])
+	if got, want := drain(pq), []int{1, 5, 7, 9}; !slices.Equal(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+	if pq.Contains(handles[1]) {
+		t.Errorf("Contains() after Pop = true, want false")
+	}
+}
+
+func TestPQRandomOperations(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	pq := NewPQ(func(a, b int) bool { return a < b })
+	var handles []*Handle[int]
+	var want []int // the values that should still be queued
+
+	for op := 0; op < 2000; op++ {
+		switch {
+		case len(handles) == 0 || rng.Intn(3) == 0:
+			v := rng.Intn(1000)
+			handles = append(handles, pq.Push(v))
+			want = append(want, v)
+		case rng.Intn(2) == 0:
+			i := rng.Intn(len(handles))
+			old, v := handles[i].Value, rng.Intn(1000)
+			pq.Update(handles[i], v)
+			want[slices.Index(want, old)] = v
+		default:
+			i := rng.Intn(len(handles))
+			v := pq.Remove(handles[i])
+			want = slices.Delete(want, slices.Index(want, v), slices.Index(want, v)+1)
+			handles = slices.Delete(handles, i, i+1)
+		}
+		if pq.Len() != len(want) {
+			t.Fatalf("op %d: Len() = %d, want %d", op, pq.Len(), len(want))
+		}
+	}
+
+	slices.Sort(want)
+	if got := drain(pq); !slices.Equal(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+}
+
+func TestBucketQueueMonotone(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	bq := NewBucketQueue[int]()
+	// The model holds the queued priorities; values are their own priority
+	var model []int
+	last := 0
+
+	for step := 0; step < 5000; step++ {
+		if len(model) == 0 || rng.Intn(3) > 0 {
+			// Like a search with edge costs up to 7, never push below the last popped priority
+			priority := last + rng.Intn(8)
+			bq.Push(priority, priority)
+			model = append(model, priority)
+		} else {
+			value, priority := bq.Pop()
+			slices.Sort(model)
+			if value != priority || priority != model[0] {
+				t.Fatalf("step %d: Pop() = %d, %d; want %d", step, value, priority, model[0])
+			}
+			model = model[1:]
+			last = priority
+		}
+		if bq.Len() != len(model) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, bq.Len(), len(model))
+		}
+	}
+}
+
+func TestBucketQueueClampsLowPriorities(t *testing.T) {
+	bq := NewBucketQueue[string]()
+	bq.Push("b", 5)
+	bq.Push("a", 3)
+	if value, priority := bq.Pop(); value != "a" || priority != 3 {
+		t.Fatalf("Pop() = %q, %d; want a, 3", value, priority)
+	}
+
+	// A priority below the last popped one is raised to it
+	bq.Push("c", 1)
+	if value, priority := bq.Pop(); value != "c" || priority != 3 {
+		t.Errorf("Pop() = %q, %d; want c, 3", value, priority)
+	}
+	if value, priority := bq.Pop(); value != "b" || priority != 5 {
+		t.Errorf("Pop() = %q, %d; want b, 5", value, priority)
+	}
+	if bq.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", bq.Len())
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+// Number is any integer or floating-point type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// SummedArea is a 2D prefix-sum (summed-area) table over a width×height grid.
+// After an O(width·height) build, the sum of any rectangle takes O(1).
+// Coordinates are 0-indexed with x across and y down.
+type SummedArea[N Number] struct {
+	width, height int
+	sums          []N // (width+1)×(height+1), row-major, with a zero first row and column
+}
+
+// NewSummedArea builds a summed-area table whose cell values come from value(x, y).
+func NewSummedArea[N Number](width, height int, value func(x, y int) N) *SummedArea[N] {
+	sa := &SummedArea[N]{
+		width:  width,
+		height: height,
+		sums:   make([]N, (width+1)*(height+1)),
+	}
+	stride := width + 1
+	for y := 1; y <= height; y++ {
+		for x := 1; x <= width; x++ {
+			sa.sums[y*stride+x] = value(x-1, y-1) +
+				sa.sums[(y-1)*stride+x] +
+				sa.sums[y*stride+x-1] -
+				sa.sums[(y-1)*stride+x-1]
+		}
+	}
+	return sa
+}
+
+// Width returns the width of the underlying grid.
+func (sa *SummedArea[N]) Width() int {
+	return sa.width
+}
+
+// Height returns the height of the underlying grid.
+func (sa *SummedArea[N]) Height() int {
+	return sa.height
+}
+
+// Sum returns the total of the w×h rectangle whose top-left cell is (x, y).
+// The rectangle must lie within the grid.
+func (sa *SummedArea[N]) Sum(x, y, w, h int) N {
+	stride := sa.width + 1
+	x2, y2 := x+w, y+h
+	return sa.sums[y2*stride+x2] -
+		sa.sums[y*stride+x2] -
+		sa.sums[y2*stride+x] +
+		sa.sums[y*stride+x]
+}
+
+// DiffGrid is a 2D difference array: each rectangle addition costs O(1),
+// and the per-cell totals are recovered with one prefix-sum pass.
+// Coordinates are 0-indexed with x across and y down.
+type DiffGrid[N Number] struct {
+	width, height int
+	diff          []N // (width+1)×(height+1), row-major
+}
+
+// NewDiffGrid creates an all-zero difference array for a width×height grid.
+func NewDiffGrid[N Number](width, height int) *DiffGrid[N] {
+	return &DiffGrid[N]{
+		width:  width,
+		height: height,
+		diff:   make([]N, (width+1)*(height+1)),
+	}
+}
+
+// AddRect adds delta to every cell of the w×h rectangle whose top-left cell is (x, y).
+// The rectangle must lie within the grid.
+func (d *DiffGrid[N]) AddRect(x, y, w, h int, delta N) {
+	stride := d.width + 1
+	x2, y2 := x+w, y+h
+	d.diff[y*stride+x] += delta
+	d.diff[y*stride+x2] -= delta
+	d.diff[y2*stride+x] -= delta
+	d.diff[y2*stride+x2] += delta
+}
+
+// Totals returns the accumulated value of every cell, indexed [y][x].
+func (d *DiffGrid[N]) Totals() [][]N {
+	stride := d.width + 1
+	totals := make([][]N, d.height)
+	for y := range totals {
+		totals[y] = make([]N, d.width)
+		var rowSum N
+		for x := 0; x < d.width; x++ {
+			rowSum += d.diff[y*stride+x]
+			totals[y][x] = rowSum
+			if y > 0 {
+				totals[y][x] += totals[y-1][x]
+			}
+		}
+	}
+	return totals
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSummedAreaMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(36))
+	for _, size := range [][2]int{{1, 1}, {1, 5}, {5, 1}, {7, 4}} {
+		width, height := size[0], size[1]
+		grid := make([][]int, height)
+		for y := range grid {
+			grid[y] = make([]int, width)
+			for x := range grid[y] {
+				grid[y][x] = rng.Intn(21) - 10
+			}
+		}
+		sa := NewSummedArea(width, height, func(x, y int) int { return grid[y][x] })
+		if sa.Width() != width || sa.Height() != height {
+			t.Errorf("size = %dx%d, want %dx%d", sa.Width(), sa.Height(), width, height)
+		}
+
+		// Every rectangle, including empty ones and those touching each edge and corner
+		for x := 0; x <= width; x++ {
+			for y := 0; y <= height; y++ {
+				for w := 0; x+w <= width; w++ {
+					for h := 0; y+h <= height; h++ {
+						want := 0
+						for yy := y; yy < y+h; yy++ {
+							for xx := x; xx < x+w; xx++ {
+								want += grid[yy][xx]
+							}
+						}
+						if got := sa.Sum(x, y, w, h); got != want {
+							t.Errorf("%dx%d grid: Sum(%d, %d, %d, %d) = %d, want %d", width, height, x, y, w, h, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDiffGridMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(36))
+	const width, height = 9, 6
+	d := NewDiffGrid[float64](width, height)
+	var want [height][width]float64
+
+	for i := 0; i < 100; i++ {
+		x, y := rng.Intn(width), rng.Intn(height)
+		w, h := rng.Intn(width-x+1), rng.Intn(height-y+1)
+		delta := float64(rng.Intn(9) - 4)
+		d.AddRect(x, y, w, h, delta)
+		for yy := y; yy < y+h; yy++ {
+			for xx := x; xx < x+w; xx++ {
+				want[yy][xx] += delta
+			}
+		}
+	}
+
+	totals := d.Totals()
+	if len(totals) != height || len(totals[0]) != width {
+		t.Fatalf("Totals() is %dx%d, want %dx%d", len(totals[0]), len(totals), width, height)
+	}
+	for y := range totals {
+		for x := range totals[y] {
+			if totals[y][x] != want[y][x] {
+				t.Errorf("Totals()[%d][%d] = %v, want %v", y, x, totals[y][x], want[y][x])
+			}
+		}
+	}
+
+	// A rectangle covering the whole grid reaches the far corner
+	full := NewDiffGrid[int](3, 2)
+	full.AddRect(0, 0, 3, 2, 5)
+	if got := full.Totals(); got[0][0] != 5 || got[1][2] != 5 {
+		t.Errorf("full-grid AddRect totals = %v, want all 5", got)
+	}
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Assignment records one task being worked on in a Schedule.
+// Workers are numbered from 0; the task occupies its worker from Start until End.
+type Assignment struct {
+	Task   string
+	Worker int
+	Start  int
+	End    int
+}
+
+// Schedule is the result of ScheduleTasks.
+type Schedule struct {
+	Order       []string     // tasks in the order they were started
+	Assignments []Assignment // one per task, in start order
+	Duration    int          // time at which the last task finished
+	Workers     int
+}
+
+// ScheduleOptions configures ScheduleTasks.
+// Workers defaults to 1, Duration to 1 per task, and Less to lexicographic order.
+type ScheduleOptions struct {
+	Workers  int
+	Duration func(task string) int
+	Less     func(a, b string) bool
+}
+
+// ScheduleTasks simulates workers completing tasks that depend on each other.
+// edges maps a task to the tasks that cannot start until it is finished.
+// Whenever workers are idle they take ready tasks in Less order, lowest-numbered
+// worker first. Tasks finishing at the same time release their dependents together.
+// Returns an error if the dependencies contain a cycle.
+func ScheduleTasks(nodes []string, edges map[string][]string, opts ScheduleOptions) (*Schedule, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.Duration == nil {
+		opts.Duration = func(string) int { return 1 }
+	}
+	if opts.Less == nil {
+		opts.Less = func(a, b string) bool { return a < b }
+	}
+
+	inDegree := make(map[string]int)
+	for _, node := range nodes {
+		inDegree[node] = 0
+	}
+	for _, dependents := range edges {
+		for _, dependent := range dependents {
+			inDegree[dependent]++
+		}
+	}
+
+	ready := NewPQ(opts.Less)
+	for _, node := range nodes {
+		if inDegree[node] == 0 {
+			ready.Push(node)
+		}
+	}
+
+	schedule := &Schedule{Workers: opts.Workers}
+	running := make([]*Assignment, opts.Workers) // current assignment per worker, nil when idle
+	now := 0
+	finished := 0
+
+	for {
+		// Hand ready tasks to idle workers
+		for worker := range running {
+			if running[worker] == nil && ready.Len() > 0 {
+				task := ready.Pop()
+				running[worker] = &Assignment{Task: task, Worker: worker, Start: now, End: now + opts.Duration(task)}
+				schedule.Order = append(schedule.Order, task)
+			}
+		}
+
+		// Jump to the next time a worker finishes
+		next := -1
+		for _, a := range running {
+			if a != nil && (next < 0 || a.End < next) {
+				next = a.End
+			}
+		}
+		if next < 0 {
+			break
+		}
+		now = next
+
+		for worker, a := range running {
+			if a == nil || a.End != now {
+				continue
+			}
+			schedule.Assignments = append(schedule.Assignments, *a)
+			running[worker] = nil
+			finished++
+			for _, dependent := range edges[a.Task] {
+				inDegree[dependent]--
+				if inDegree[dependent] == 0 {
+					ready.Push(dependent)
+				}
+			}
+		}
+	}
+
+	if finished != len(inDegree) {
+		return nil, fmt.Errorf("dependency cycle: %d of %d tasks could not be scheduled", len(inDegree)-finished, len(inDegree))
+	}
+
+	sort.SliceStable(schedule.Assignments, func(i, j int) bool {
+		a, b := schedule.Assignments[i], schedule.Assignments[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.Worker < b.Worker
+	})
+	schedule.Duration = now
+	return schedule, nil
+}
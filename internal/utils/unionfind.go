@@ -3,65 +3,231 @@ package utils
 
 // UnionFind implements a disjoint-set data structure with path compression and union by rank.
 // This is useful for efficiently tracking connected components.
-type UnionFind struct {
-	parent map[int]int
-	rank   map[int]int
+// Elements can be any comparable type; see DenseUnionFind for elements numbered 0..n-1.
+type UnionFind[K comparable] struct {
+	parent map[K]K
+	rank   map[K]int
+	size   map[K]int
+	order  []K // insertion order, so component listings are deterministic
+	sets   int
 }
 
 // NewUnionFind creates a new UnionFind structure.
-func NewUnionFind() *UnionFind {
-	return &UnionFind{
-		parent: make(map[int]int),
-		rank:   make(map[int]int),
+func NewUnionFind[K comparable]() *UnionFind[K] {
+	return &UnionFind[K]{
+		parent: make(map[K]K),
+		rank:   make(map[K]int),
+		size:   make(map[K]int),
 	}
 }
 
 // MakeSet adds a new element to the UnionFind structure.
-func (uf *UnionFind) MakeSet(x int) {
+func (uf *UnionFind[K]) MakeSet(x K) {
 	if _, exists := uf.parent[x]; !exists {
 		uf.parent[x] = x
 		uf.rank[x] = 0
+		uf.size[x] = 1
+		uf.order = append(uf.order, x)
+		uf.sets++
 	}
 }
 
 // Find returns the root of the set containing x, with path compression.
-func (uf *UnionFind) Find(x int) int {
-	if uf.parent[x] != x {
-		uf.parent[x] = uf.Find(uf.parent[x]) // Path compression
+// Unknown elements are added as singleton sets, so finding a key that was never
+// added changes CountSets and Components; use Lookup to query without adding.
+func (uf *UnionFind[K]) Find(x K) K {
+	uf.MakeSet(x)
+	root, _ := uf.Lookup(x)
+	return root
+}
+
+// Lookup returns the root of the set containing x, with path compression,
+// and false without adding x if it is not an element.
+func (uf *UnionFind[K]) Lookup(x K) (K, bool) {
+	parent, ok := uf.parent[x]
+	if !ok {
+		return x, false
+	}
+	if parent != x {
+		parent, _ = uf.Lookup(parent)
+		uf.parent[x] = parent // Path compression
 	}
-	return uf.parent[x]
+	return parent, true
 }
 
 // Union merges the sets containing x and y using union by rank.
-func (uf *UnionFind) Union(x, y int) {
+// Returns true if x and y were in different sets.
+func (uf *UnionFind[K]) Union(x, y K) bool {
 	rootX := uf.Find(x)
 	rootY := uf.Find(y)
-	
+
 	if rootX == rootY {
-		return
+		return false
 	}
-	
+
 	// Union by rank
 	if uf.rank[rootX] < uf.rank[rootY] {
-		uf.parent[rootX] = rootY
-	} else if uf.rank[rootX] > uf.rank[rootY] {
-		uf.parent[rootY] = rootX
-	} else {
-		uf.parent[rootY] = rootX
+		rootX, rootY = rootY, rootX
+	} else if uf.rank[rootX] == uf.rank[rootY] {
 		uf.rank[rootX]++
 	}
+	uf.parent[rootY] = rootX
+	uf.size[rootX] += uf.size[rootY]
+	delete(uf.size, rootY)
+	uf.sets--
+	return true
+}
+
+// Connected returns true if x and y are elements of the same set.
+// Unlike Find it does not add unknown elements.
+func (uf *UnionFind[K]) Connected(x, y K) bool {
+	rootX, okX := uf.Lookup(x)
+	rootY, okY := uf.Lookup(y)
+	return okX && okY && rootX == rootY
+}
+
+// CountSets returns the number of disjoint sets.
+func (uf *UnionFind[K]) CountSets() int {
+	return uf.sets
+}
+
+// Size returns the number of elements in the set containing x, or 0 if x is not an element.
+func (uf *UnionFind[K]) Size(x K) int {
+	root, ok := uf.Lookup(x)
+	if !ok {
+		return 0
+	}
+	return uf.size[root]
+}
+
+// Members returns the elements in the set containing x, in insertion order,
+// or nil if x is not an element.
+func (uf *UnionFind[K]) Members(x K) []K {
+	root, ok := uf.Lookup(x)
+	if !ok {
+		return nil
+	}
+	members := make([]K, 0, uf.size[root])
+	for _, node := range uf.order {
+		if uf.Find(node) == root {
+			members = append(members, node)
+		}
+	}
+	return members
+}
+
+// Components returns every set as a slice of its elements.
+// Sets are ordered by their first-inserted element, and elements by insertion order.
+func (uf *UnionFind[K]) Components() [][]K {
+	index := make(map[K]int, uf.sets)
+	components := make([][]K, 0, uf.sets)
+	for _, node := range uf.order {
+		root := uf.Find(node)
+		i, ok := index[root]
+		if !ok {
+			i = len(components)
+			index[root] = i
+			components = append(components, make([]K, 0, uf.size[root]))
+		}
+		components[i] = append(components[i], node)
+	}
+	return components
+}
+
+// DenseUnionFind is a slice-backed disjoint-set for elements numbered 0..n-1.
+// It avoids map lookups entirely, which makes it much faster than UnionFind
+// when elements are already indices (e.g. positions in an input slice).
+type DenseUnionFind struct {
+	parent []int
+	size   []int
+	sets   int
+}
+
+// NewDenseUnionFind creates n singleton sets for the elements 0..n-1.
+func NewDenseUnionFind(n int) *DenseUnionFind {
+	uf := &DenseUnionFind{
+		parent: make([]int, n),
+		size:   make([]int, n),
+		sets:   n,
+	}
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.size[i] = 1
+	}
+	return uf
+}
+
+// Find returns the root of the set containing x, halving the path as it goes.
+func (uf *DenseUnionFind) Find(x int) int {
+	for uf.parent[x] != x {
+		uf.parent[x] = uf.parent[uf.parent[x]]
+		x = uf.parent[x]
+	}
+	return x
+}
+
+// Union merges the sets containing x and y, attaching the smaller set to the larger.
+// Returns true if x and y were in different sets.
+func (uf *DenseUnionFind) Union(x, y int) bool {
+	rootX := uf.Find(x)
+	rootY := uf.Find(y)
+
+	if rootX == rootY {
+		return false
+	}
+
+	// Union by size
+	if uf.size[rootX] < uf.size[rootY] {
+		rootX, rootY = rootY, rootX
+	}
+	uf.parent[rootY] = rootX
+	uf.size[rootX] += uf.size[rootY]
+	uf.sets--
+	return true
 }
 
 // Connected returns true if x and y are in the same set.
-func (uf *UnionFind) Connected(x, y int) bool {
+func (uf *DenseUnionFind) Connected(x, y int) bool {
 	return uf.Find(x) == uf.Find(y)
 }
 
 // CountSets returns the number of disjoint sets.
-func (uf *UnionFind) CountSets() int {
-	roots := make(map[int]bool)
+func (uf *DenseUnionFind) CountSets() int {
+	return uf.sets
+}
+
+// Size returns the number of elements in the set containing x.
+func (uf *DenseUnionFind) Size(x int) int {
+	return uf.size[uf.Find(x)]
+}
+
+// Members returns the elements in the set containing x, in ascending order.
+func (uf *DenseUnionFind) Members(x int) []int {
+	root := uf.Find(x)
+	members := make([]int, 0, uf.size[root])
 	for node := range uf.parent {
-		roots[uf.Find(node)] = true
+		if uf.Find(node) == root {
+			members = append(members, node)
+		}
 	}
-	return len(roots)
-}
\ No newline at end of file
+	return members
+}
+
+// Components returns every set as a slice of its elements.
+// Sets are ordered by their smallest element, and elements ascend.
+func (uf *DenseUnionFind) Components() [][]int {
+	index := make([]int, len(uf.parent))
+	for i := range index {
+		index[i] = -1
+	}
+	components := make([][]int, 0, uf.sets)
+	for node := range uf.parent {
+		root := uf.Find(node)
+		if index[root] < 0 {
+			index[root] = len(components)
+			components = append(components, make([]int, 0, uf.size[root]))
+		}
+		components[index[root]] = append(components[index[root]], node)
+	}
+	return components
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestUnionFind(t *testing.T) {
+	uf := NewUnionFind[string]()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		uf.MakeSet(name)
+	}
+	if !uf.Union("a", "c") || !uf.Union("d", "c") || uf.Union("a", "d") {
+		t.Errorf("Union() results wrong: want true, true, then false for an already joined pair")
+	}
+
+	if uf.CountSets() != 3 || uf.Size("d") != 3 {
+		t.Errorf("CountSets(), Size(d) = %d, %d, want 3, 3", uf.CountSets(), uf.Size("d"))
+	}
+	if !uf.Connected("a", "d") || uf.Connected("a", "b") {
+		t.Errorf("Connected() disagrees with the unions made")
+	}
+	if got := uf.Members("c"); !slices.Equal(got, []string{"a", "c", "d"}) {
+		t.Errorf("Members(c) = %v, want [a c d]", got)
+	}
+	if got := fmt.Sprint(uf.Components()); got != "[[a c d] [b] [e]]" {
+		t.Errorf("Components() = %s, want [[a c d] [b] [e]]", got)
+	}
+}
+
+func TestUnionFindUnknownKeys(t *testing.T) {
+	uf := NewUnionFind[string]()
+	uf.Union("a", "b")
+
+	// Queries leave unknown keys out
+	if _, ok := uf.Lookup("typo"); ok {
+		t.Errorf("Lookup(typo) ok = true, want false")
+	}
+	if uf.Connected("a", "typo") || uf.Size("typo") != 0 || uf.Members("typo") != nil {
+		t.Errorf("queries on an unknown key should report nothing")
+	}
+	if uf.CountSets() != 1 || len(uf.Components()) != 1 {
+		t.Errorf("queries added a set: CountSets() = %d, Components() = %v", uf.CountSets(), uf.Components())
+	}
+
+	// Find adds them, as documented
+	if root := uf.Find("c"); root != "c" || uf.CountSets() != 2 {
+		t.Errorf("Find(c) = %q with %d sets, want c with 2", root, uf.CountSets())
+	}
+}
+
+func TestDenseUnionFindMatchesUnionFind(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	const n = 200
+	dense := NewDenseUnionFind(n)
+	sparse := NewUnionFind[int]()
+	for i := 0; i < n; i++ {
+		sparse.MakeSet(i)
+	}
+
+	for step := 0; step < 150; step++ {
+		x, y := rng.Intn(n), rng.Intn(n)
+		if a, b := dense.Union(x, y), sparse.Union(x, y); a != b {
+			t.Fatalf("Union(%d, %d) = %v, map-backed %v", x, y, a, b)
+		}
+		if dense.CountSets() != sparse.CountSets() || dense.Size(x) != sparse.Size(x) {
+			t.Fatalf("after Union(%d, %d): %d sets of which x's has %d, map-backed %d and %d",
+				x, y, dense.CountSets(), dense.Size(x), sparse.CountSets(), sparse.Size(x))
+		}
+	}
+
+	// Both list components by first element with members ascending
+	if a, b := fmt.Sprint(dense.Components()), fmt.Sprint(sparse.Components()); a != b {
+		t.Errorf("Components() = %s, map-backed %s", a, b)
+	}
+	for x := 0; x < n; x += 17 {
+		if a, b := dense.Members(x), sparse.Members(x); !slices.Equal(a, b) {
+			t.Errorf("Members(%d) = %v, map-backed %v", x, a, b)
+		}
+		if y := (x * 7) % n; dense.Connected(x, y) != sparse.Connected(x, y) {
+			t.Errorf("Connected(%d, %d) disagrees", x, y)
+		}
+	}
+}
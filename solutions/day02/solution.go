@@ -5,13 +5,16 @@
  * and separately counting those with exactly three of any letter, then multiply.
  *
  * Part 2: Find the two correct box IDs that differ by exactly one character at the
- * same position, then return the common letters between them.
+ * same position, then return the common letters between them. Masking one position
+ * at a time and hashing the rest with rolling hashes finds the pair in O(n·L) rather
+ * than the O(n²·L) of comparing all pairs.
  */
 
 package day02
 
 import (
 	"errors"
+	"sort"
 	"strings"
 )
 
@@ -68,47 +71,191 @@ func (s *Solution) Part1() (int, error) {
 func (s *Solution) Part2() (string, error) {
 	lines := s.parseLines()
 
-	for i := 0; i < len(lines); i++ {
-		for j := i + 1; j < len(lines); j++ {
-			if diffByOne(lines[i], lines[j]) {
-				return commonLetters(lines[i], lines[j]), nil
-			}
+	i, j, ok := findOneApart(lines)
+	if !ok {
+		return "", errors.New("no pair of IDs differing by exactly one character was found")
+	}
+	return commonLetters(lines[i], lines[j]), nil
+}
+
+// findOneApart returns the first pair of IDs (by the earlier index, then the later)
+// that differ in exactly one position. Deleting a position from both IDs of such a
+// pair leaves the same string, so each position is masked in turn and the remainders
+// looked up in a set. The remainders are keyed by rolling hashes of the prefix before
+// and the suffix after the masked position, which every ID computes for all positions
+// in O(L), so the whole search takes O(n·L) rather than the O(n²·L) of comparing every
+// pair. Hash matches are confirmed by comparing the IDs.
+func findOneApart(ids []string) (int, int, bool) {
+	// prefixes[offsets[j]+pos] hashes ids[j][:pos] and suffixes[offsets[j]+pos] hashes ids[j][pos:]
+	offsets := make([]int, len(ids)+1)
+	maxLen := 0
+	for j, id := range ids {
+		offsets[j+1] = offsets[j] + len(id) + 1
+		maxLen = max(maxLen, len(id))
+	}
+	prefixes := make([]uint64, offsets[len(ids)])
+	suffixes := make([]uint64, offsets[len(ids)])
+	for j, id := range ids {
+		off := offsets[j]
+		for pos := 0; pos < len(id); pos++ {
+			prefixes[off+pos+1] = prefixes[off+pos]*hashBase + uint64(id[pos])
+		}
+		for pos := len(id) - 1; pos >= 0; pos-- {
+			suffixes[off+pos] = suffixes[off+pos+1]*hashBase + uint64(id[pos])
 		}
 	}
 
-	return "", errors.New("no pair of IDs differing by exactly one character was found")
-}
+	type maskedKey struct {
+		length         int
+		prefix, suffix uint64
+	}
+	keyAt := func(j, pos int) maskedKey {
+		off := offsets[j]
+		return maskedKey{length: len(ids[j]), prefix: prefixes[off+pos], suffix: suffixes[off+pos+1]}
+	}
+	oneApart := func(i, j int) bool {
+		d, ok := hammingWithin(ids[i], ids[j], 1)
+		return ok && d == 1
+	}
 
-func (s *Solution) parseLines() []string {
-	lines := strings.Split(s.input, "\n")
-	result := make([]string, 0, len(lines))
+	bestI, bestJ := -1, -1
+	consider := func(i, j int) {
+		if bestI < 0 || i < bestI || (i == bestI && j < bestJ) {
+			bestI, bestJ = i, j
+		}
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			result = append(result, line)
+	seen := make(map[maskedKey]int, len(ids))
+	for pos := 0; pos < maxLen; pos++ {
+		clear(seen)
+		for j, id := range ids {
+			if pos >= len(id) {
+				continue
+			}
+			key := keyAt(j, pos)
+			i, ok := seen[key]
+			if !ok {
+				seen[key] = j
+				continue
+			}
+			// Any later ID matching an identical pair also matches the earlier of the two
+			if ids[i] == id {
+				continue
+			}
+			if oneApart(i, j) {
+				consider(i, j)
+				continue
+			}
+			// The hashes collided, so the earliest ID with this key may not be the match
+			for i := i + 1; i < j; i++ {
+				if pos < len(ids[i]) && keyAt(i, pos) == key && oneApart(i, j) {
+					consider(i, j)
+					break
+				}
+			}
 		}
 	}
 
-	return result
+	return bestI, bestJ, bestI >= 0
+}
+
+// hashBase is the multiplier of the polynomial hashes in findOneApart, which wrap modulo 2⁶⁴.
+const hashBase = 1_000_003
+
+// Pair is two IDs, by index, and the number of positions where they differ.
+type Pair struct {
+	I, J     int
+	Distance int
 }
 
-func diffByOne(a, b string) bool {
-	if len(a) != len(b) {
-		return false
+// PairsWithin returns every pair of equal-length IDs that differ in at most k
+// positions, ordered by I then J. Splitting each ID into k+1 blocks, two IDs within
+// distance k must match exactly on at least one block, so only IDs sharing a block
+// are compared.
+func PairsWithin(ids []string, k int) []Pair {
+	if k < 0 {
+		return nil
 	}
+	byLength := make(map[int][]int)
+	for i, id := range ids {
+		byLength[len(id)] = append(byLength[len(id)], i)
+	}
+
+	found := make(map[[2]int]bool)
+	var pairs []Pair
+	for length, group := range byLength {
+		if length <= k {
+			// Every pair is within k, including pairs that differ in every position and
+			// so share no block; with no more than k positions, compare them all
+			for x := 0; x < len(group); x++ {
+				for y := x + 1; y < len(group); y++ {
+					d, _ := hammingWithin(ids[group[x]], ids[group[y]], k)
+					pairs = append(pairs, Pair{I: group[x], J: group[y], Distance: d})
+				}
+			}
+			continue
+		}
+		blocks := k + 1
+		for b := 0; b < blocks; b++ {
+			// Block b covers positions [start, end)
+			start, end := b*length/blocks, (b+1)*length/blocks
+			buckets := make(map[string][]int)
+			for _, i := range group {
+				buckets[ids[i][start:end]] = append(buckets[ids[i][start:end]], i)
+			}
+			for _, bucket := range buckets {
+				for x := 0; x < len(bucket); x++ {
+					for y := x + 1; y < len(bucket); y++ {
+						i, j := bucket[x], bucket[y]
+						if found[[2]int{i, j}] {
+							continue
+						}
+						if d, ok := hammingWithin(ids[i], ids[j], k); ok {
+							found[[2]int{i, j}] = true
+							pairs = append(pairs, Pair{I: i, J: j, Distance: d})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(a, b int) bool {
+		if pairs[a].I != pairs[b].I {
+			return pairs[a].I < pairs[b].I
+		}
+		return pairs[a].J < pairs[b].J
+	})
+	return pairs
+}
 
+// hammingWithin returns the number of positions where a and b differ,
+// giving up once it exceeds k. The strings must have the same length.
+func hammingWithin(a, b string, k int) (int, bool) {
 	differences := 0
 	for i := 0; i < len(a); i++ {
 		if a[i] != b[i] {
 			differences++
-			if differences > 1 {
-				return false
+			if differences > k {
+				return differences, false
 			}
 		}
 	}
+	return differences, true
+}
 
-	return differences == 1
+func (s *Solution) parseLines() []string {
+	lines := strings.Split(s.input, "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
 }
 
 func commonLetters(a, b string) string {
@@ -1,7 +1,11 @@
 package day02
 
 import (
+	"fmt"
+	"math/rand"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -89,3 +93,175 @@ func TestPart2(t *testing.T) {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
 }
+
+// pairsBruteForce compares every pair of IDs.
+func pairsBruteForce(ids []string, k int) []Pair {
+	var pairs []Pair
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			if len(ids[i]) != len(ids[j]) {
+				continue
+			}
+			if d, ok := hammingWithin(ids[i], ids[j], k); ok {
+				pairs = append(pairs, Pair{I: i, J: j, Distance: d})
+			}
+		}
+	}
+	return pairs
+}
+
+func TestPairsWithinMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for round := 0; round < 20; round++ {
+		// A small alphabet and mixed lengths make near pairs and duplicates common
+		ids := make([]string, 200)
+		for i := range ids {
+			id := make([]byte, 5+rng.Intn(3))
+			for j := range id {
+				id[j] = byte('a' + rng.Intn(3))
+			}
+			ids[i] = string(id)
+		}
+
+		for k := 0; k <= 3; k++ {
+			got := PairsWithin(ids, k)
+			want := pairsBruteForce(ids, k)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round %d: PairsWithin(k=%d) found %d pairs, want %d", round, k, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestPairsWithinShortIDs(t *testing.T) {
+	// With k at or above the length, pairs differing in every position share no block
+	tests := []struct {
+		ids  []string
+		k    int
+		want []Pair
+	}{
+		{[]string{"a", "b"}, 1, []Pair{{I: 0, J: 1, Distance: 1}}},
+		{[]string{"ab", "cd", "ab"}, 2, []Pair{{I: 0, J: 1, Distance: 2}, {I: 0, J: 2}, {I: 1, J: 2, Distance: 2}}},
+		{[]string{"ab", "cd", "x"}, 5, []Pair{{I: 0, J: 1, Distance: 2}}},
+		{[]string{"", "", "a"}, 0, []Pair{{I: 0, J: 1}}},
+		{[]string{"abc", "xyz", "abz"}, 2, []Pair{{I: 0, J: 2, Distance: 1}, {I: 1, J: 2, Distance: 2}}},
+	}
+	for _, tt := range tests {
+		got := PairsWithin(tt.ids, tt.k)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PairsWithin(%q, %d) = %v, want %v", tt.ids, tt.k, got, tt.want)
+		}
+		if brute := pairsBruteForce(tt.ids, tt.k); !reflect.DeepEqual(got, brute) {
+			t.Errorf("PairsWithin(%q, %d) = %v, brute force = %v", tt.ids, tt.k, got, brute)
+		}
+	}
+}
+
+func TestFindOneApartMatchesPairwise(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for round := 0; round < 200; round++ {
+		// Short IDs over a small alphabet give duplicates, near pairs and rounds without any
+		ids := make([]string, 1+rng.Intn(15))
+		for i := range ids {
+			id := make([]byte, 1+rng.Intn(4))
+			for j := range id {
+				id[j] = byte('a' + rng.Intn(4))
+			}
+			ids[i] = string(id)
+		}
+
+		i, j, ok := findOneApart(ids)
+		wantI, wantJ, wantOK := findOneApartPairwise(ids)
+		if ok != wantOK || (ok && (i != wantI || j != wantJ)) {
+			t.Fatalf("findOneApart(%q) = %d, %d, %v; want %d, %d, %v", ids, i, j, ok, wantI, wantJ, wantOK)
+		}
+	}
+}
+
+func TestFindOneApartPicksFirstPair(t *testing.T) {
+	ids := []string{"abcde", "zzzzz", "abcdf", "zzzzy", "abcdf", "abcdg"}
+
+	i, j, ok := findOneApart(ids)
+	if !ok || i != 0 || j != 2 {
+		t.Errorf("findOneApart() = %d, %d, %v; want 0, 2, true", i, j, ok)
+	}
+
+	var oneApart []Pair
+	for _, pair := range PairsWithin(ids, 1) {
+		if pair.Distance == 1 {
+			oneApart = append(oneApart, pair)
+		}
+	}
+	if len(oneApart) == 0 || oneApart[0].I != i || oneApart[0].J != j {
+		t.Errorf("PairsWithin(k=1) first one-apart pair = %v, want {%d %d 1}", oneApart, i, j)
+	}
+}
+
+// generateIDs returns n random 26-letter IDs with one planted pair one letter apart.
+func generateIDs(n int) []string {
+	rng := rand.New(rand.NewSource(4))
+	ids := make([]string, n)
+	for i := range ids {
+		id := make([]byte, 26)
+		for j := range id {
+			id[j] = byte('a' + rng.Intn(26))
+		}
+		ids[i] = string(id)
+	}
+	planted := []byte(ids[n/3])
+	planted[7] = planted[7]%26 + 'b'
+	if planted[7] > 'z' {
+		planted[7] = 'a'
+	}
+	ids[2*n/3] = string(planted)
+	return ids
+}
+
+// findOneApartPairwise is the earlier Part 2: compare every pair of IDs.
+func findOneApartPairwise(ids []string) (int, int, bool) {
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			if len(ids[i]) != len(ids[j]) {
+				continue
+			}
+			if d, ok := hammingWithin(ids[i], ids[j], 1); ok && d == 1 {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func BenchmarkPart2(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		ids := generateIDs(n)
+		input := strings.Join(ids, "\n")
+
+		b.Run(fmt.Sprintf("masked/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				New(input).Part2()
+			}
+		})
+		// Comparing every pair of 100k IDs takes around a hundred times longer, so only the smaller set is timed
+		if n <= 10_000 {
+			b.Run(fmt.Sprintf("pairwise/%d", n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					findOneApartPairwise(ids)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkPairsWithin(b *testing.B) {
+	ids := generateIDs(100_000)
+	for _, k := range []int{1, 2, 3} {
+		b.Run(fmt.Sprintf("k=%d", k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				PairsWithin(ids, k)
+			}
+		})
+	}
+}
@@ -2,27 +2,35 @@
  * Day 3: No Matter How You Slice It
  *
  * Part 1: Count square inches of fabric that are within two or more claims.
- * Parse fabric claims in format "#ID @ x,y: wxh" and track overlapping areas
- * on a 2D grid to count squares claimed by multiple elves.
+ * Parse fabric claims in format "#ID @ x,y: wxh" and sweep across the claim
+ * edges to measure the area claimed by multiple elves without painting the fabric.
  *
  * Part 2: Find the ID of the claim that doesn't overlap with any other claim.
- * This requires tracking which specific claims occupy each square inch.
+ * The same sweep finds every overlapping pair of claims.
+ *
+ * Heatmap renders the claim counts per square inch as a PNG for checking parsing by eye.
  */
 
 package day03
 
 import (
 	"errors"
-	"regexp"
-	"strconv"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/spatial"
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 type Claim struct {
-	ID     int
+	ID     int `parse:"id"`
 	X, Y   int
-	Width  int
-	Height int
+	Width  int `parse:"w"`
+	Height int `parse:"h"`
 }
 
 type Solution struct {
@@ -39,27 +47,8 @@ func (s *Solution) Part1() (int, error) {
 		return 0, err
 	}
 
-	// Track how many claims each square inch has
-	fabric := make(map[string]int)
-
-	for _, claim := range claims {
-		for x := claim.X; x < claim.X+claim.Width; x++ {
-			for y := claim.Y; y < claim.Y+claim.Height; y++ {
-				key := strconv.Itoa(x) + "," + strconv.Itoa(y)
-				fabric[key]++
-			}
-		}
-	}
-
 	// Count squares with 2+ claims
-	overlapping := 0
-	for _, count := range fabric {
-		if count >= 2 {
-			overlapping++
-		}
-	}
-
-	return overlapping, nil
+	return claimSet(claims).CoveredArea(2), nil
 }
 
 func (s *Solution) Part2() (int, error) {
@@ -68,89 +57,151 @@ func (s *Solution) Part2() (int, error) {
 		return 0, err
 	}
 
-	// Track which claims occupy each square inch
-	fabric := make(map[string][]int)
-
-	for _, claim := range claims {
-		for x := claim.X; x < claim.X+claim.Width; x++ {
-			for y := claim.Y; y < claim.Y+claim.Height; y++ {
-				key := strconv.Itoa(x) + "," + strconv.Itoa(y)
-				fabric[key] = append(fabric[key], claim.ID)
-			}
-		}
+	// Return the claim that's not overlapping
+	for _, i := range claimSet(claims).Isolated() {
+		return claims[i].ID, nil
 	}
 
-	// Find claims that never overlap with others
-	overlapping := make(map[int]bool)
-	for _, claimIDs := range fabric {
-		if len(claimIDs) > 1 {
-			for _, id := range claimIDs {
-				overlapping[id] = true
-			}
-		}
+	return 0, errors.New("no non-overlapping claim found")
+}
+
+// OverlappingClaims returns the IDs of the claims sharing at least one square inch
+// with the claim with the given ID, in input order.
+func (s *Solution) OverlappingClaims(id int) ([]int, error) {
+	claims, err := s.parseClaims()
+	if err != nil {
+		return nil, err
 	}
 
-	// Return the claim that's not overlapping
-	for _, claim := range claims {
-		if !overlapping[claim.ID] {
-			return claim.ID, nil
+	for i, claim := range claims {
+		if claim.ID != id {
+			continue
+		}
+		var ids []int
+		for _, j := range claimSet(claims).Overlapping(i) {
+			ids = append(ids, claims[j].ID)
 		}
+		return ids, nil
 	}
 
-	return 0, errors.New("no non-overlapping claim found")
+	return nil, fmt.Errorf("claim #%d not found", id)
 }
 
-func (s *Solution) parseClaims() ([]Claim, error) {
-	lines := strings.Split(s.input, "\n")
-	claims := make([]Claim, 0, len(lines))
+// maxHeatmapPixels caps the fabric area Heatmap will render
+const maxHeatmapPixels = 1 << 24
 
-	// Regular expression to parse format: #123 @ 3,2: 5x4
-	re := regexp.MustCompile(`^#(\d+) @ (\d+),(\d+): (\d+)x(\d+)$`)
+// HeatmapOptions configures Heatmap.
+type HeatmapOptions struct {
+	// HighlightIntact outlines the claim that overlaps no other, if there is one
+	HighlightIntact bool
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// Heatmap renders the fabric with one pixel per square inch, coloured by how many
+// claims cover it: black for none, blue for one, and yellow through red for two
+// up to the most claims on any square inch.
+func (s *Solution) Heatmap(opts HeatmapOptions) (*image.RGBA, error) {
+	claims, err := s.parseClaims()
+	if err != nil {
+		return nil, err
+	}
 
-		matches := re.FindStringSubmatch(line)
-		if len(matches) != 6 {
-			return nil, errors.New("invalid claim format: " + line)
-		}
+	width, height := fabricSize(claims)
+	if width*height > maxHeatmapPixels {
+		return nil, fmt.Errorf("fabric of %dx%d square inches is too large to render", width, height)
+	}
 
-		id, err := strconv.Atoi(matches[1])
-		if err != nil {
-			return nil, err
-		}
+	fabric := utils.NewDiffGrid[int](width, height)
+	for _, claim := range claims {
+		fabric.AddRect(claim.X, claim.Y, claim.Width, claim.Height, 1)
+	}
+	counts := fabric.Totals()
 
-		x, err := strconv.Atoi(matches[2])
-		if err != nil {
-			return nil, err
+	maxCount := 0
+	for _, row := range counts {
+		for _, count := range row {
+			maxCount = max(maxCount, count)
 		}
+	}
 
-		y, err := strconv.Atoi(matches[3])
-		if err != nil {
-			return nil, err
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, row := range counts {
+		for x, count := range row {
+			img.SetRGBA(x, y, heatColor(count, maxCount))
 		}
+	}
 
-		width, err := strconv.Atoi(matches[4])
-		if err != nil {
-			return nil, err
+	if opts.HighlightIntact {
+		for _, i := range claimSet(claims).Isolated() {
+			r := claims[i].rect()
+			green := color.RGBA{G: 255, A: 255}
+			for x := r.MinX; x < r.MaxX; x++ {
+				img.SetRGBA(x, r.MinY, green)
+				img.SetRGBA(x, r.MaxY-1, green)
+			}
+			for y := r.MinY; y < r.MaxY; y++ {
+				img.SetRGBA(r.MinX, y, green)
+				img.SetRGBA(r.MaxX-1, y, green)
+			}
 		}
+	}
 
-		height, err := strconv.Atoi(matches[5])
-		if err != nil {
-			return nil, err
-		}
+	return img, nil
+}
+
+// WriteHeatmapPNG writes Heatmap to w as a PNG.
+func (s *Solution) WriteHeatmapPNG(w io.Writer, opts HeatmapOptions) error {
+	img, err := s.Heatmap(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// heatColor maps a claim count to its heatmap colour.
+func heatColor(count, maxCount int) color.RGBA {
+	switch count {
+	case 0:
+		return color.RGBA{A: 255}
+	case 1:
+		return color.RGBA{R: 40, G: 70, B: 140, A: 255}
+	}
+
+	// Fade from yellow at two claims to red at the most crowded square inch
+	t := 1.0
+	if maxCount > 2 {
+		t = float64(count-2) / float64(maxCount-2)
+	}
+	return color.RGBA{
+		R: uint8(255 - 35*t),
+		G: uint8(200 * (1 - t)),
+		A: 255,
+	}
+}
 
-		claims = append(claims, Claim{
-			ID:     id,
-			X:      x,
-			Y:      y,
-			Width:  width,
-			Height: height,
-		})
+// fabricSize returns the smallest fabric dimensions that fit every claim.
+func fabricSize(claims []Claim) (width, height int) {
+	for _, claim := range claims {
+		width = max(width, claim.X+claim.Width)
+		height = max(height, claim.Y+claim.Height)
+	}
+	return width, height
+}
+
+// rect returns the fabric area a claim covers.
+func (c Claim) rect() spatial.Rect {
+	return spatial.Rect{MinX: c.X, MinY: c.Y, MaxX: c.X + c.Width, MaxY: c.Y + c.Height}
+}
+
+// claimSet indexes the claims' rectangles for overlap queries, in claim order.
+func claimSet(claims []Claim) *spatial.RectSet {
+	rects := make([]spatial.Rect, len(claims))
+	for i, claim := range claims {
+		rects[i] = claim.rect()
 	}
+	return spatial.NewRectSet(rects)
+}
 
-	return claims, nil
+func (s *Solution) parseClaims() ([]Claim, error) {
+	// Claims have the format: #123 @ 3,2: 5x4
+	return utils.ParseLines[Claim](s.input, "#{id} @ {x},{y}: {w}x{h}")
 }
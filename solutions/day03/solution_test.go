@@ -1,7 +1,14 @@
 package day03
 
 import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"math/rand"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +88,144 @@ func TestPart2(t *testing.T) {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
 }
+
+func TestOverlappingClaimsExample(t *testing.T) {
+	input := `#1 @ 1,3: 4x4
+#2 @ 3,1: 4x4
+#3 @ 5,5: 2x2`
+
+	solution := New(input)
+	for id, expected := range map[int][]int{1: {2}, 2: {1}, 3: nil} {
+		result, err := solution.OverlappingClaims(id)
+		if err != nil {
+			t.Fatalf("OverlappingClaims(%d) error = %v", id, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("OverlappingClaims(%d) = %v, want %v", id, result, expected)
+		}
+	}
+
+	if _, err := solution.OverlappingClaims(4); err == nil {
+		t.Errorf("OverlappingClaims(4) succeeded for a missing claim")
+	}
+}
+
+func TestLargeCoordinates(t *testing.T) {
+	// A painted fabric for these claims would have trillions of square inches
+	input := `#1 @ 1000000,2000000: 3000000x1000000
+#2 @ 2000000,2500000: 5000000x2000000
+#3 @ 9000000,9000000: 10x10`
+
+	solution := New(input)
+
+	overlap, err := solution.Part1()
+	if err != nil {
+		t.Fatalf("Part1() error = %v", err)
+	}
+	if expected := 2000000 * 500000; overlap != expected {
+		t.Errorf("Part1() = %v, want %v", overlap, expected)
+	}
+
+	intact, err := solution.Part2()
+	if err != nil {
+		t.Fatalf("Part2() error = %v", err)
+	}
+	if intact != 3 {
+		t.Errorf("Part2() = %v, want 3", intact)
+	}
+}
+
+func TestMatchesPaintedFabric(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for round := 0; round < 50; round++ {
+		var lines []string
+		fabric := make(map[[2]int][]int)
+		for id := 1; id <= 30; id++ {
+			x, y := rng.Intn(40), rng.Intn(40)
+			w, h := 1+rng.Intn(10), 1+rng.Intn(10)
+			lines = append(lines, fmt.Sprintf("#%d @ %d,%d: %dx%d", id, x, y, w, h))
+			for dx := 0; dx < w; dx++ {
+				for dy := 0; dy < h; dy++ {
+					fabric[[2]int{x + dx, y + dy}] = append(fabric[[2]int{x + dx, y + dy}], id)
+				}
+			}
+		}
+
+		overlapArea := 0
+		overlapping := make(map[int]map[int]bool)
+		for _, ids := range fabric {
+			if len(ids) > 1 {
+				overlapArea++
+			}
+			for _, a := range ids {
+				for _, b := range ids {
+					if a != b {
+						if overlapping[a] == nil {
+							overlapping[a] = make(map[int]bool)
+						}
+						overlapping[a][b] = true
+					}
+				}
+			}
+		}
+
+		solution := New(strings.Join(lines, "\n"))
+		result, err := solution.Part1()
+		if err != nil {
+			t.Fatalf("Part1() error = %v", err)
+		}
+		if result != overlapArea {
+			t.Fatalf("round %d: Part1() = %d, want %d", round, result, overlapArea)
+		}
+
+		for id := 1; id <= 30; id++ {
+			ids, err := solution.OverlappingClaims(id)
+			if err != nil {
+				t.Fatalf("OverlappingClaims(%d) error = %v", id, err)
+			}
+			if len(ids) != len(overlapping[id]) {
+				t.Fatalf("round %d: OverlappingClaims(%d) = %v, want %d claims", round, id, ids, len(overlapping[id]))
+			}
+			for _, other := range ids {
+				if !overlapping[id][other] {
+					t.Fatalf("round %d: OverlappingClaims(%d) includes %d", round, id, other)
+				}
+			}
+		}
+	}
+}
+
+func TestHeatmapExample(t *testing.T) {
+	input := `#1 @ 1,3: 4x4
+#2 @ 3,1: 4x4
+#3 @ 5,5: 2x2`
+
+	var buf bytes.Buffer
+	if err := New(input).WriteHeatmapPNG(&buf, HeatmapOptions{HighlightIntact: true}); err != nil {
+		t.Fatalf("WriteHeatmapPNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 7 || bounds.Dy() != 7 {
+		t.Fatalf("heatmap size = %dx%d, want 7x7", bounds.Dx(), bounds.Dy())
+	}
+
+	tests := []struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		{0, 0, heatColor(0, 2)},            // unclaimed
+		{1, 3, heatColor(1, 2)},            // claim 1 only
+		{3, 3, heatColor(2, 2)},            // claims 1 and 2
+		{5, 5, color.RGBA{G: 255, A: 255}}, // intact claim 3, outlined
+	}
+	for _, tt := range tests {
+		got := color.RGBAModel.Convert(img.At(tt.x, tt.y)).(color.RGBA)
+		if got != tt.expected {
+			t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, got, tt.expected)
+		}
+	}
+}
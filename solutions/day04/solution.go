@@ -7,12 +7,17 @@
  *
  * Part 2: Find the guard most frequently asleep on the same minute across all days.
  * Return the product of that guard's ID and the minute they're most often asleep.
+ *
+ * Analyze exposes the per-guard minute histograms and night-by-night shifts, which
+ * can be rendered as the puzzle's schedule chart, text histograms, or JSON.
  */
 
 package day04
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
@@ -35,8 +40,60 @@ type Event struct {
 }
 
 type GuardSleep struct {
-	TotalMinutes int
-	MinuteCounts [60]int // Index is minute, value is count of times asleep at that minute
+	ID           int     `json:"id"`
+	TotalMinutes int     `json:"totalMinutes"`
+	MinuteCounts [60]int `json:"minuteCounts"` // Index is minute, value is count of times asleep at that minute
+}
+
+// MostAsleepMinute returns the minute the guard was asleep on most often and how
+// many times, preferring the earliest minute on ties. A guard who never slept gets 0, 0.
+func (g *GuardSleep) MostAsleepMinute() (minute, count int) {
+	for m, c := range g.MinuteCounts {
+		if c > count {
+			minute, count = m, c
+		}
+	}
+	return minute, count
+}
+
+// Nap is a stretch of sleep within the midnight hour, from Start up to but not including End.
+type Nap struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Shift is one guard's night on duty.
+type Shift struct {
+	Date    string `json:"date"` // Day of the midnight hour, as YYYY-MM-DD
+	GuardID int    `json:"guard"`
+	Naps    []Nap  `json:"naps"`
+}
+
+// Minutes returns the shift's midnight hour as 60 characters, '#' for asleep and '.' for awake.
+func (sh Shift) Minutes() string {
+	row := []byte(strings.Repeat(".", 60))
+	for _, nap := range sh.Naps {
+		for minute := nap.Start; minute < nap.End; minute++ {
+			row[minute] = '#'
+		}
+	}
+	return string(row)
+}
+
+// SleepAnalysis is everything the records say about the guards' sleep.
+type SleepAnalysis struct {
+	Guards map[int]*GuardSleep `json:"guards"`
+	Shifts []Shift             `json:"shifts"` // In chronological order
+}
+
+// GuardIDs returns the IDs of every guard seen, in ascending order.
+func (a *SleepAnalysis) GuardIDs() []int {
+	ids := make([]int, 0, len(a.Guards))
+	for id := range a.Guards {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
 }
 
 type Solution struct {
@@ -48,18 +105,16 @@ func New(input string) *Solution {
 }
 
 func (s *Solution) Part1() (int, error) {
-	events, err := s.parseEvents()
+	analysis, err := s.Analyze()
 	if err != nil {
 		return 0, err
 	}
 
-	guards := s.analyzeGuardSleep(events)
-
 	// Find guard with most total sleep minutes
 	var sleepiestGuard int
 	var maxSleep int
-	for guardID, sleep := range guards {
-		if sleep.TotalMinutes > maxSleep {
+	for _, guardID := range analysis.GuardIDs() {
+		if sleep := analysis.Guards[guardID]; sleep.TotalMinutes > maxSleep {
 			maxSleep = sleep.TotalMinutes
 			sleepiestGuard = guardID
 		}
@@ -70,39 +125,28 @@ func (s *Solution) Part1() (int, error) {
 	}
 
 	// Find the minute this guard sleeps most often
-	sleepData := guards[sleepiestGuard]
-	var mostSleptMinute int
-	var maxCount int
-	for minute, count := range sleepData.MinuteCounts {
-		if count > maxCount {
-			maxCount = count
-			mostSleptMinute = minute
-		}
-	}
+	mostSleptMinute, _ := analysis.Guards[sleepiestGuard].MostAsleepMinute()
 
 	return sleepiestGuard * mostSleptMinute, nil
 }
 
 func (s *Solution) Part2() (int, error) {
-	events, err := s.parseEvents()
+	analysis, err := s.Analyze()
 	if err != nil {
 		return 0, err
 	}
 
-	guards := s.analyzeGuardSleep(events)
-
 	// Find the guard who is most frequently asleep on the same minute
 	var bestGuard int
 	var bestMinute int
 	var maxFrequency int
 
-	for guardID, sleep := range guards {
-		for minute, count := range sleep.MinuteCounts {
-			if count > maxFrequency {
-				maxFrequency = count
-				bestGuard = guardID
-				bestMinute = minute
-			}
+	for _, guardID := range analysis.GuardIDs() {
+		minute, count := analysis.Guards[guardID].MostAsleepMinute()
+		if count > maxFrequency {
+			maxFrequency = count
+			bestGuard = guardID
+			bestMinute = minute
 		}
 	}
 
@@ -170,33 +214,49 @@ func (s *Solution) parseEvents() ([]Event, error) {
 	return events, nil
 }
 
-func (s *Solution) analyzeGuardSleep(events []Event) map[int]*GuardSleep {
-	guards := make(map[int]*GuardSleep)
-	var currentGuard int
+// Analyze replays the records into per-guard sleep totals and the night-by-night shifts.
+func (s *Solution) Analyze() (*SleepAnalysis, error) {
+	events, err := s.parseEvents()
+	if err != nil {
+		return nil, err
+	}
+	return analyzeGuardSleep(events), nil
+}
+
+func analyzeGuardSleep(events []Event) *SleepAnalysis {
+	analysis := &SleepAnalysis{Guards: make(map[int]*GuardSleep)}
+	var shift *Shift
 	var sleepStart int
 
 	for _, event := range events {
 		switch event.Type {
 		case BeginShift:
-			currentGuard = event.GuardID
-			if _, exists := guards[currentGuard]; !exists {
-				guards[currentGuard] = &GuardSleep{}
+			if _, exists := analysis.Guards[event.GuardID]; !exists {
+				analysis.Guards[event.GuardID] = &GuardSleep{ID: event.GuardID}
+			}
+
+			// Shifts starting before midnight belong to the next day's midnight hour
+			date := event.Time
+			if date.Hour() != 0 {
+				date = date.AddDate(0, 0, 1)
 			}
+			analysis.Shifts = append(analysis.Shifts, Shift{Date: date.Format("2006-01-02"), GuardID: event.GuardID})
+			shift = &analysis.Shifts[len(analysis.Shifts)-1]
 
 		case FallAsleep:
 			sleepStart = event.Time.Minute()
 
 		case WakeUp:
-			if currentGuard == 0 {
+			if shift == nil {
 				continue // Skip if no current guard
 			}
 
 			sleepEnd := event.Time.Minute()
-			sleepDuration := sleepEnd - sleepStart
+			shift.Naps = append(shift.Naps, Nap{Start: sleepStart, End: sleepEnd})
 
 			// Update guard's sleep data
-			guard := guards[currentGuard]
-			guard.TotalMinutes += sleepDuration
+			guard := analysis.Guards[shift.GuardID]
+			guard.TotalMinutes += sleepEnd - sleepStart
 
 			// Mark each minute the guard was asleep
 			for minute := sleepStart; minute < sleepEnd; minute++ {
@@ -205,5 +265,72 @@ func (s *Solution) analyzeGuardSleep(events []Event) map[int]*GuardSleep {
 		}
 	}
 
-	return guards
+	return analysis
+}
+
+// ScheduleChart renders the shifts in the layout of the puzzle's example:
+// the month-day, the guard on duty, and '#' for each minute asleep.
+func (a *SleepAnalysis) ScheduleChart() string {
+	idWidth := len("ID") + 3
+	for _, shift := range a.Shifts {
+		idWidth = max(idWidth, len("#"+strconv.Itoa(shift.GuardID))+2)
+	}
+	indent := strings.Repeat(" ", len("MM-DD  ")+idWidth)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-7s%-*sMinute\n", "Date", idWidth, "ID")
+	sb.WriteString(minuteAxis(indent))
+	for _, shift := range a.Shifts {
+		fmt.Fprintf(&sb, "\n%-7s%-*s%s", shift.Date[len("YYYY-"):], idWidth, "#"+strconv.Itoa(shift.GuardID), shift.Minutes())
+	}
+	return sb.String()
+}
+
+// Histograms renders, for each guard in ID order, a bar chart of how many
+// nights they were asleep at each minute of the midnight hour.
+func (a *SleepAnalysis) Histograms() string {
+	var sections []string
+	for _, id := range a.GuardIDs() {
+		guard := a.Guards[id]
+		minute, count := guard.MostAsleepMinute()
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Guard #%d: %d minutes asleep", id, guard.TotalMinutes)
+		if count > 0 {
+			fmt.Fprintf(&sb, ", most often at minute %d (%d nights)", minute, count)
+		}
+		for level := count; level > 0; level-- {
+			row := []byte(strings.Repeat(" ", 60))
+			for m, c := range guard.MinuteCounts {
+				if c >= level {
+					row[m] = '#'
+				}
+			}
+			fmt.Fprintf(&sb, "\n%3d %s", level, strings.TrimRight(string(row), " "))
+		}
+		sb.WriteString("\n" + minuteAxis(strings.Repeat(" ", 4)))
+		sections = append(sections, sb.String())
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Report combines the schedule chart and the per-guard histograms.
+func (a *SleepAnalysis) Report() string {
+	return a.ScheduleChart() + "\n\n" + a.Histograms()
+}
+
+// JSON encodes the analysis for further processing elsewhere.
+func (a *SleepAnalysis) JSON() ([]byte, error) {
+	return json.MarshalIndent(a, "", "  ")
+}
+
+// minuteAxis returns the two rows labelling minutes 0-59 with their tens and
+// ones digits, each starting with indent.
+func minuteAxis(indent string) string {
+	var tens, ones strings.Builder
+	for minute := 0; minute < 60; minute++ {
+		tens.WriteByte(byte('0' + minute/10))
+		ones.WriteByte(byte('0' + minute%10))
+	}
+	return indent + tens.String() + "\n" + indent + ones.String()
 }
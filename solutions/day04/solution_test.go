@@ -1,7 +1,9 @@
 package day04
 
 import (
+	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +111,93 @@ func TestPart2(t *testing.T) {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
 }
+
+const exampleRecords = `[1518-11-01 00:00] Guard #10 begins shift
+[1518-11-01 00:05] falls asleep
+[1518-11-01 00:25] wakes up
+[1518-11-01 00:30] falls asleep
+[1518-11-01 00:55] wakes up
+[1518-11-01 23:58] Guard #99 begins shift
+[1518-11-02 00:40] falls asleep
+[1518-11-02 00:50] wakes up
+[1518-11-03 00:05] Guard #10 begins shift
+[1518-11-03 00:24] falls asleep
+[1518-11-03 00:29] wakes up
+[1518-11-04 00:02] Guard #99 begins shift
+[1518-11-04 00:36] falls asleep
+[1518-11-04 00:46] wakes up
+[1518-11-05 00:03] Guard #99 begins shift
+[1518-11-05 00:45] falls asleep
+[1518-11-05 00:55] wakes up`
+
+func TestScheduleChartExample(t *testing.T) {
+	analysis, err := New(exampleRecords).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	expected := `Date   ID   Minute
+            000000000011111111112222222222333333333344444444445555555555
+            012345678901234567890123456789012345678901234567890123456789
+11-01  #10  .....####################.....#########################.....
+11-02  #99  ........................................##########..........
+11-03  #10  ........................#####...............................
+11-04  #99  ....................................##########..............
+11-05  #99  .............................................##########.....`
+
+	if result := analysis.ScheduleChart(); result != expected {
+		t.Errorf("ScheduleChart() =\n%s\nwant\n%s", result, expected)
+	}
+}
+
+func TestHistogramsExample(t *testing.T) {
+	analysis, err := New(exampleRecords).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	histograms := analysis.Histograms()
+	lines := strings.Split(histograms, "\n")
+
+	expectedHeaders := []string{
+		"Guard #10: 50 minutes asleep, most often at minute 24 (2 nights)",
+		"Guard #99: 30 minutes asleep, most often at minute 45 (3 nights)",
+	}
+	for _, header := range expectedHeaders {
+		if !strings.Contains(histograms, header) {
+			t.Errorf("Histograms() missing %q in\n%s", header, histograms)
+		}
+	}
+
+	// Guard #10 slept twice only at minute 24
+	if lines[1] != "  2                         #" {
+		t.Errorf("Histograms() top bar = %q", lines[1])
+	}
+}
+
+func TestAnalysisJSON(t *testing.T) {
+	analysis, err := New(exampleRecords).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	data, err := analysis.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	var decoded SleepAnalysis
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if decoded.Guards[99].TotalMinutes != 30 || decoded.Guards[99].MinuteCounts[45] != 3 {
+		t.Errorf("decoded guard #99 = %+v", decoded.Guards[99])
+	}
+	if len(decoded.Shifts) != 5 || decoded.Shifts[1].Date != "1518-11-02" || decoded.Shifts[1].GuardID != 99 {
+		t.Errorf("decoded shifts = %+v", decoded.Shifts)
+	}
+	if got := decoded.Shifts[0].Naps; len(got) != 2 || got[1] != (Nap{Start: 30, End: 55}) {
+		t.Errorf("decoded naps on 11-01 = %+v", got)
+	}
+}
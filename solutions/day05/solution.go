@@ -2,23 +2,26 @@
  * Day 5: Alchemical Reduction
  *
  * Part 1: Simulate polymer reactions where adjacent units of same type but opposite
- * polarity destroy each other. Use a stack to efficiently process reactions until
- * no more reactions are possible. Return the final polymer length.
+ * polarity destroy each other. A stack-based reactor processes units as they are
+ * streamed in, keeping only the survivors. Return the final polymer length.
  *
  * Part 2: Find the polymer unit type that, when completely removed, results in the
  * shortest final polymer after reactions. Try removing each letter type (a/A, b/B, etc.)
- * and find which gives the minimum result.
+ * and find which gives the minimum result. Each removal starts from Part 1's reacted
+ * polymer, and all 26 run in parallel.
  */
 
 package day05
 
 import (
+	"io"
 	"strings"
-	"unicode"
+	"sync"
 )
 
 type Solution struct {
-	input string
+	input   string
+	reduced []byte // the fully reacted polymer, computed on first use
 }
 
 func New(input string) *Solution {
@@ -26,74 +29,105 @@ func New(input string) *Solution {
 }
 
 func (s *Solution) Part1() (int, error) {
-	return s.reactPolymer(s.input), nil
+	return len(s.reduce()), nil
 }
 
 func (s *Solution) Part2() (int, error) {
-	minLength := len(s.input)
+	// Removing a unit type and reacting gives the same result whether or not the
+	// polymer was reacted first, so start from Part 1's much shorter polymer
+	return ShortestWithoutOneType(s.reduce()), nil
+}
 
-	// Try removing each unit type (a/A, b/B, c/C, etc.)
-	for r := 'a'; r <= 'z'; r++ {
-		// Skip if neither lower nor upper case exists to save work
-		if !strings.ContainsRune(s.input, r) && !strings.ContainsRune(s.input, unicode.ToUpper(r)) {
-			continue
-		}
+// reduce returns the fully reacted input, reacting it on first use.
+func (s *Solution) reduce() []byte {
+	if s.reduced == nil {
+		reactor := NewReactor()
+		reactor.Write([]byte(s.input))
+		s.reduced = reactor.Polymer()
+	}
+	return s.reduced
+}
 
-		// Create polymer without this unit type and react
-		filtered := s.removeUnitType(s.input, r)
-		length := s.reactPolymer(filtered)
+// Reactor reacts a polymer as its units arrive. Only the surviving units are
+// kept, so a polymer of any length can be streamed through it.
+type Reactor struct {
+	units []byte // surviving units, used as a stack
+	skip  byte   // lower-case unit type removed before reacting, or 0 to keep every type
+}
 
-		if length < minLength {
-			minLength = length
-			// Can't beat zero; short-circuit
-			if minLength == 0 {
-				break
-			}
-		}
-	}
+// NewReactor creates a reactor that keeps every unit type.
+func NewReactor() *Reactor {
+	return &Reactor{units: []byte{}}
+}
 
-	return minLength, nil
+// NewReactorWithout creates a reactor that removes both polarities of unitType
+// before they can react.
+func NewReactorWithout(unitType byte) *Reactor {
+	return &Reactor{units: []byte{}, skip: unitType | 0x20}
 }
 
-// reactPolymer simulates polymer reactions using a stack approach
-func (s *Solution) reactPolymer(polymer string) int {
-	if len(polymer) == 0 {
-		return 0
+// React streams a polymer from src through a new reactor.
+func React(src io.Reader) (*Reactor, error) {
+	reactor := NewReactor()
+	if _, err := io.Copy(reactor, src); err != nil {
+		return nil, err
 	}
+	return reactor, nil
+}
 
-	stack := make([]rune, 0, len(polymer))
-
-	for _, unit := range polymer {
-		if len(stack) > 0 && s.canReact(stack[len(stack)-1], unit) {
-			// Remove the last unit from stack (reaction destroys both units)
-			stack = stack[:len(stack)-1]
-		} else {
-			// Add unit to stack
-			stack = append(stack, unit)
+// Write adds units to the polymer, reacting each against the units before it.
+// Whitespace is ignored. It never fails, so the reactor can be an io.Writer.
+func (r *Reactor) Write(p []byte) (int, error) {
+	for _, unit := range p {
+		switch {
+		case unit == ' ' || unit == '\n' || unit == '\r' || unit == '\t':
+			continue
+		case r.skip != 0 && unit|0x20 == r.skip:
+			continue
+		case len(r.units) > 0 && canReact(r.units[len(r.units)-1], unit):
+			// The reaction destroys both units
+			r.units = r.units[:len(r.units)-1]
+		default:
+			r.units = append(r.units, unit)
 		}
 	}
+	return len(p), nil
+}
 
-	return len(stack)
+// Len returns the number of units left after every reaction so far.
+func (r *Reactor) Len() int {
+	return len(r.units)
 }
 
-// canReact checks if two units can react (same type, opposite polarity)
-func (s *Solution) canReact(a, b rune) bool {
-	// Same type but opposite polarity means one is uppercase and other is lowercase
-	// and they represent the same letter
-	return unicode.ToLower(a) == unicode.ToLower(b) && a != b
+// Polymer returns a copy of the units left after every reaction so far.
+func (r *Reactor) Polymer() []byte {
+	return append([]byte(nil), r.units...)
 }
 
-// removeUnitType removes all instances of a specific unit type (both polarities)
-func (s *Solution) removeUnitType(polymer string, unitType rune) string {
-	var result strings.Builder
-	result.Grow(len(polymer)) // Pre-allocate capacity
+// ShortestWithoutOneType returns the shortest polymer length reachable by removing
+// every unit of one type and reacting the rest. The 26 types are tried in parallel.
+func ShortestWithoutOneType(polymer []byte) int {
+	var lengths [26]int
+	var wg sync.WaitGroup
+	for i := range lengths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reactor := NewReactorWithout(byte('a' + i))
+			reactor.Write(polymer)
+			lengths[i] = reactor.Len()
+		}()
+	}
+	wg.Wait()
 
-	for _, unit := range polymer {
-		if unicode.ToLower(unit) == unitType {
-			continue
-		}
-		result.WriteRune(unit)
+	minLength := len(polymer)
+	for _, length := range lengths {
+		minLength = min(minLength, length)
 	}
+	return minLength
+}
 
-	return result.String()
+// canReact checks if two units can react: the same letter in opposite cases
+func canReact(a, b byte) bool {
+	return a^b == 0x20 && a|0x20 >= 'a' && a|0x20 <= 'z'
 }
@@ -1,8 +1,14 @@
 package day05
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode"
 )
 
 func readInput(t *testing.T) string {
@@ -90,3 +96,147 @@ func TestPart2(t *testing.T) {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
 }
+
+func TestReactStreaming(t *testing.T) {
+	input := readInput(t)
+
+	// Feeding the reactor one byte at a time must match reacting the whole input
+	reactor, err := React(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("React() error = %v", err)
+	}
+	if reactor.Len() != 10878 {
+		t.Errorf("React() left %d units, want 10878", reactor.Len())
+	}
+
+	whole := NewReactor()
+	whole.Write([]byte(input))
+	if !bytes.Equal(reactor.Polymer(), whole.Polymer()) {
+		t.Errorf("streamed polymer differs from reacting the whole input")
+	}
+}
+
+func TestPolymerIsACopy(t *testing.T) {
+	reactor := NewReactor()
+	reactor.Write([]byte("aAbc"))
+	polymer := reactor.Polymer()
+	polymer[1] = 'C'
+
+	// Had the change reached the reactor, the C would react with the incoming c
+	reactor.Write([]byte("cd"))
+	if got := string(reactor.Polymer()); got != "bccd" {
+		t.Errorf("polymer = %q, want %q", got, "bccd")
+	}
+}
+
+func TestReactorWithout(t *testing.T) {
+	tests := []struct {
+		unitType byte
+		expected string
+	}{
+		{'a', "dbCBcD"},
+		{'B', "daCAcaDA"},
+		{'c', "daDA"},
+		{'d', "abCBAc"},
+	}
+
+	for _, tt := range tests {
+		reactor := NewReactorWithout(tt.unitType)
+		reactor.Write([]byte("dabAcCaCBAcCcaDA\n"))
+		if got := string(reactor.Polymer()); got != tt.expected {
+			t.Errorf("without %c: polymer = %q, want %q", tt.unitType, got, tt.expected)
+		}
+	}
+}
+
+// generatePolymer builds a random polymer of n units with plenty of reacting pairs.
+func generatePolymer(n int) []byte {
+	rng := rand.New(rand.NewSource(5))
+	polymer := make([]byte, 0, n)
+	for len(polymer) < n {
+		unit := byte('a' + rng.Intn(26))
+		if rng.Intn(2) == 0 {
+			unit -= 0x20
+		}
+		polymer = append(polymer, unit)
+		// Often follow with the opposite polarity to set up reactions
+		if rng.Intn(3) == 0 && len(polymer) < n {
+			polymer = append(polymer, unit^0x20)
+		}
+	}
+	return polymer
+}
+
+// part2Strings is the earlier Part 2: filter the whole input string for each unit
+// type and react it again, one type at a time.
+func part2Strings(input string) int {
+	react := func(polymer string) int {
+		stack := make([]rune, 0, len(polymer))
+		for _, unit := range polymer {
+			if len(stack) > 0 && unicode.ToLower(stack[len(stack)-1]) == unicode.ToLower(unit) && stack[len(stack)-1] != unit {
+				stack = stack[:len(stack)-1]
+			} else {
+				stack = append(stack, unit)
+			}
+		}
+		return len(stack)
+	}
+
+	minLength := len(input)
+	for r := 'a'; r <= 'z'; r++ {
+		filtered := strings.Map(func(unit rune) rune {
+			if unicode.ToLower(unit) == r {
+				return -1
+			}
+			return unit
+		}, input)
+		minLength = min(minLength, react(filtered))
+	}
+	return minLength
+}
+
+func TestPart2MatchesStrings(t *testing.T) {
+	polymer := string(generatePolymer(20000))
+	result, err := New(polymer).Part2()
+	if err != nil {
+		t.Fatalf("Part2() error = %v", err)
+	}
+	if expected := part2Strings(polymer); result != expected {
+		t.Errorf("Part2() = %d, want %d", result, expected)
+	}
+}
+
+// BenchmarkReact streams generated polymers through a reactor.
+func BenchmarkReact(b *testing.B) {
+	for _, size := range []int{1 << 20, 8 << 20} {
+		polymer := generatePolymer(size)
+		b.Run(fmt.Sprintf("%dMB", size>>20), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := React(bytes.NewReader(polymer)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPart2 compares the reduced, parallel search with re-reacting the whole
+// input for each unit type.
+func BenchmarkPart2(b *testing.B) {
+	polymer := string(generatePolymer(4 << 20))
+
+	b.Run("reduced-parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			New(polymer).Part2()
+		}
+	})
+	b.Run("strings", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			part2Strings(polymer)
+		}
+	})
+}
@@ -4,16 +4,26 @@
  * Part 1: Find the largest finite area in a coordinate grid using Manhattan distance.
  * For each point in the grid, determine which input coordinate is closest (using Manhattan
  * distance). Count the area for each coordinate, but exclude coordinates with infinite
- * areas (those that extend to the edge of the grid).
+ * areas (those that extend to the edge of the grid). Voronoi exposes the labelled map,
+ * the areas, and the infinite regions, and WritePNG draws it.
  *
  * Part 2: Find the size of the region containing all locations which have a total distance
  * to all given coordinates of less than 10000. For each point, calculate the sum of Manhattan
  * distances to all coordinates and count how many locations have a total distance < 10000.
+ * The X and Y parts of that sum are independent, so each axis gets a sorted profile of its
+ * distance sums and the region size is the number of pairs adding up to less than the limit.
  */
 
 package day06
 
 import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -57,113 +67,289 @@ func New(input string) *Solution {
 }
 
 func (s *Solution) Part1() (int, error) {
-	// Find the bounding box
+	v, err := s.Voronoi()
+	if err != nil {
+		return 0, err
+	}
+	_, area := v.LargestFiniteArea()
+	return area, nil
+}
+
+// Tie labels a location equally close to two or more coordinates.
+const Tie = -1
+
+// Voronoi labels every location in the coordinates' bounding box with its closest coordinate.
+type Voronoi struct {
+	Min, Max utils.Point // Corners of the bounding box, inclusive
+	Labels   [][]int     // Labels[y-Min.Y][x-Min.X] is the closest coordinate's index, or Tie
+	Areas    []int       // Number of locations in the box closest to each coordinate
+
+	// Infinite maps each coordinate whose region is unbounded to a location on the
+	// edge of the box that it owns. Stepping outward from an edge location moves
+	// away from every coordinate by the same amount, so its closest coordinate
+	// never changes and the region goes on forever.
+	Infinite map[int]utils.Point
+}
+
+// Voronoi computes the labelled map of the coordinates' bounding box.
+// It returns an error if there are no coordinates, since there is then no box.
+func (s *Solution) Voronoi() (*Voronoi, error) {
+	if len(s.coordinates) == 0 {
+		return nil, errors.New("no coordinates")
+	}
 	minX, maxX := s.coordinates[0].X, s.coordinates[0].X
 	minY, maxY := s.coordinates[0].Y, s.coordinates[0].Y
-
 	for _, coord := range s.coordinates {
-		if coord.X < minX {
-			minX = coord.X
-		}
-		if coord.X > maxX {
-			maxX = coord.X
-		}
-		if coord.Y < minY {
-			minY = coord.Y
+		minX, maxX = min(minX, coord.X), max(maxX, coord.X)
+		minY, maxY = min(minY, coord.Y), max(maxY, coord.Y)
+	}
+
+	v := &Voronoi{
+		Min:      utils.Point{X: minX, Y: minY},
+		Max:      utils.Point{X: maxX, Y: maxY},
+		Labels:   make([][]int, maxY-minY+1),
+		Areas:    make([]int, len(s.coordinates)),
+		Infinite: make(map[int]utils.Point),
+	}
+	for y := minY; y <= maxY; y++ {
+		row := make([]int, maxX-minX+1)
+		for x := minX; x <= maxX; x++ {
+			closest := s.findClosestCoordinate(utils.Point{X: x, Y: y})
+			row[x-minX] = closest
+			if closest == Tie {
+				continue
+			}
+			v.Areas[closest]++
+
+			// If this point is on the edge, the coordinate has infinite area
+			if x == minX || x == maxX || y == minY || y == maxY {
+				if _, seen := v.Infinite[closest]; !seen {
+					v.Infinite[closest] = utils.Point{X: x, Y: y}
+				}
+			}
 		}
-		if coord.Y > maxY {
-			maxY = coord.Y
+		v.Labels[y-minY] = row
+	}
+	return v, nil
+}
+
+// Label returns the index of the coordinate closest to p, or Tie.
+// p must lie within the bounding box.
+func (v *Voronoi) Label(p utils.Point) int {
+	return v.Labels[p.Y-v.Min.Y][p.X-v.Min.X]
+}
+
+// LargestFiniteArea returns the coordinate with the largest bounded region and its
+// area, preferring the lower index on ties. The index is -1 if every region is infinite.
+func (v *Voronoi) LargestFiniteArea() (index, area int) {
+	index = -1
+	for i, a := range v.Areas {
+		if _, infinite := v.Infinite[i]; !infinite && a > area {
+			index, area = i, a
 		}
 	}
+	return index, area
+}
 
-	// Track which coordinates have infinite areas
-	infiniteAreas := make(map[int]bool)
+// ImageOptions configures WritePNG.
+type ImageOptions struct {
+	Scale        int // pixels per location, default 2
+	SafeDistance int // locations with a total distance below this are overlaid; 0 disables the overlay
+}
 
-	// Count areas for each coordinate
-	areas := make(map[int]int)
+// WritePNG draws the Voronoi map as a PNG: each region in its own colour, infinite
+// regions darkened, ties in grey, coordinates in black, and the safe region
+// lightened when opts.SafeDistance is set.
+func (s *Solution) WritePNG(w io.Writer, opts ImageOptions) error {
+	if opts.Scale <= 0 {
+		opts.Scale = 2
+	}
 
-	// Check each point in the grid
-	for x := minX; x <= maxX; x++ {
-		for y := minY; y <= maxY; y++ {
-			closest := s.findClosestCoordinate(utils.Point{X: x, Y: y})
-			if closest != -1 {
-				areas[closest]++
+	v, err := s.Voronoi()
+	if err != nil {
+		return err
+	}
+	palette := regionColors(len(s.coordinates))
+	for i := range v.Infinite {
+		c := palette[i]
+		palette[i] = color.RGBA{R: c.R / 2, G: c.G / 2, B: c.B / 2, A: 255}
+	}
 
-				// If this point is on the edge, mark the coordinate as having infinite area
-				if x == minX || x == maxX || y == minY || y == maxY {
-					infiniteAreas[closest] = true
-				}
+	width, height := v.Max.X-v.Min.X+1, v.Max.Y-v.Min.Y+1
+	img := image.NewRGBA(image.Rect(0, 0, width*opts.Scale, height*opts.Scale))
+	for y, row := range v.Labels {
+		for x, label := range row {
+			c := color.RGBA{R: 160, G: 160, B: 160, A: 255}
+			if label != Tie {
+				c = palette[label]
 			}
+			if opts.SafeDistance > 0 && s.totalDistance(utils.Point{X: x + v.Min.X, Y: y + v.Min.Y}) < opts.SafeDistance {
+				// Blend halfway towards white
+				c = color.RGBA{R: c.R/2 + 128, G: c.G/2 + 128, B: c.B/2 + 128, A: 255}
+			}
+			fillCell(img, x, y, opts.Scale, c)
 		}
 	}
+	for _, coord := range s.coordinates {
+		fillCell(img, coord.X-v.Min.X, coord.Y-v.Min.Y, opts.Scale, color.RGBA{A: 255})
+	}
+
+	return png.Encode(w, img)
+}
 
-	// Find the largest finite area
-	maxArea := 0
-	for coordIndex, area := range areas {
-		if !infiniteAreas[coordIndex] && area > maxArea {
-			maxArea = area
+// fillCell paints the scale×scale block of pixels for location (x, y).
+func fillCell(img *image.RGBA, x, y, scale int, c color.RGBA) {
+	for dy := 0; dy < scale; dy++ {
+		for dx := 0; dx < scale; dx++ {
+			img.SetRGBA(x*scale+dx, y*scale+dy, c)
 		}
 	}
+}
 
-	return maxArea, nil
+// regionColors returns n distinct colours, stepping the hue by the golden angle
+// so neighbouring indices look different.
+func regionColors(n int) []color.RGBA {
+	colors := make([]color.RGBA, n)
+	for i := range colors {
+		hue := math.Mod(float64(i)*137.508, 360) / 60
+		// Convert hue at 65% saturation and 90% value to RGB
+		const value, saturation = 0.9, 0.65
+		chroma := value * saturation
+		second := chroma * (1 - math.Abs(math.Mod(hue, 2)-1))
+		var r, g, b float64
+		switch int(hue) {
+		case 0:
+			r, g = chroma, second
+		case 1:
+			r, g = second, chroma
+		case 2:
+			g, b = chroma, second
+		case 3:
+			g, b = second, chroma
+		case 4:
+			r, b = second, chroma
+		default:
+			r, b = chroma, second
+		}
+		m := value - chroma
+		colors[i] = color.RGBA{
+			R: uint8(255 * (r + m)),
+			G: uint8(255 * (g + m)),
+			B: uint8(255 * (b + m)),
+			A: 255,
+		}
+	}
+	return colors
 }
 
 func (s *Solution) Part2() (int, error) {
 	return s.countRegionSize(10000), nil
 }
 
-// countRegionSize counts locations where the sum of Manhattan distances to all coordinates is less than maxDistance
+// countRegionSize counts locations where the sum of Manhattan distances to all coordinates is less than maxDistance.
+// The total distance splits into an X part and a Y part, so it builds the sorted distance profile of each axis
+// and counts the pairs whose sum stays under the limit, in O(n log n + W + H) for a region W wide and H tall.
 func (s *Solution) countRegionSize(maxDistance int) int {
-	// Find the bounding box (with some padding to ensure we capture the full region)
-	minX, maxX := s.coordinates[0].X, s.coordinates[0].X
-	minY, maxY := s.coordinates[0].Y, s.coordinates[0].Y
-
-	for _, coord := range s.coordinates {
-		if coord.X < minX {
-			minX = coord.X
-		}
-		if coord.X > maxX {
-			maxX = coord.X
-		}
-		if coord.Y < minY {
-			minY = coord.Y
-		}
-		if coord.Y > maxY {
-			maxY = coord.Y
-		}
+	if len(s.coordinates) == 0 {
+		return 0
+	}
+	xs := make([]int, len(s.coordinates))
+	ys := make([]int, len(s.coordinates))
+	for i, coord := range s.coordinates {
+		xs[i], ys[i] = coord.X, coord.Y
 	}
 
-	// Add padding to ensure we don't miss edge cases
-	padding := maxDistance / len(s.coordinates)
-	minX -= padding
-	maxX += padding
-	minY -= padding
-	maxY += padding
+	// A column can only hold region cells if its X distance leaves room for the best possible Y distance
+	sort.Ints(xs)
+	sort.Ints(ys)
+	xProfile := axisProfile(xs, maxDistance-medianDistance(ys))
+	yProfile := axisProfile(ys, maxDistance-medianDistance(xs))
 
+	// Both profiles are ascending, so walk the Y profile down as the X distance grows
 	count := 0
+	j := len(yProfile)
+	for _, dx := range xProfile {
+		for j > 0 && dx+yProfile[j-1] >= maxDistance {
+			j--
+		}
+		count += j
+	}
+	return count
+}
 
-	// Check each point in the expanded grid
-	for x := minX; x <= maxX; x++ {
-		for y := minY; y <= maxY; y++ {
-			totalDistance := 0
-			point := utils.Point{X: x, Y: y}
+// medianDistance returns the sum of distances from the median of the sorted values to all of them,
+// the smallest such sum for any position on the axis.
+func medianDistance(sorted []int) int {
+	total := 0
+	for _, v := range sorted {
+		total += utils.Abs(v - sorted[len(sorted)/2])
+	}
+	return total
+}
 
-			// Calculate sum of distances to all coordinates
-			for _, coord := range s.coordinates {
-				totalDistance += manhattanDistance(point, coord)
-			}
+// axisProfile returns, in ascending order, the sum of distances to all sorted values from every
+// integer position on the axis where that sum is less than limit. The sum is convex in the position,
+// so it walks outwards from the median in both directions and merges the two non-decreasing runs.
+func axisProfile(sorted []int, limit int) []int {
+	n := len(sorted)
+	median := sorted[n/2]
+	total := medianDistance(sorted)
+	if total >= limit {
+		return nil
+	}
 
-			if totalDistance < maxDistance {
-				count++
-			}
+	// Moving right from x changes the sum by (values <= x) - (values > x); moving left by the mirror
+	var right []int
+	below := sort.SearchInts(sorted, median+1) // values <= x
+	for x, sum := median, total; ; x++ {
+		for below < n && sorted[below] <= x {
+			below++
+		}
+		sum += below - (n - below)
+		if sum >= limit {
+			break
 		}
+		right = append(right, sum)
+	}
+	var left []int
+	above := n - sort.SearchInts(sorted, median) // values >= x
+	for x, sum := median, total; ; x-- {
+		for above < n && sorted[n-above-1] >= x {
+			above++
+		}
+		sum += above - (n - above)
+		if sum >= limit {
+			break
+		}
+		left = append(left, sum)
 	}
 
-	return count
+	profile := make([]int, 0, 1+len(left)+len(right))
+	profile = append(profile, total)
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		if j == len(right) || (i < len(left) && left[i] <= right[j]) {
+			profile = append(profile, left[i])
+			i++
+		} else {
+			profile = append(profile, right[j])
+			j++
+		}
+	}
+	return profile
+}
+
+// totalDistance returns the sum of Manhattan distances from point to every coordinate
+func (s *Solution) totalDistance(point utils.Point) int {
+	total := 0
+	for _, coord := range s.coordinates {
+		total += manhattanDistance(point, coord)
+	}
+	return total
 }
 
 // findClosestCoordinate returns the index of the closest coordinate to the given point
-// Returns -1 if there's a tie (multiple coordinates at the same minimum distance)
+// Returns Tie if there's a tie (multiple coordinates at the same minimum distance)
 func (s *Solution) findClosestCoordinate(point utils.Point) int {
 	minDistance := -1
 	closestIndex := -1
@@ -182,7 +368,7 @@ func (s *Solution) findClosestCoordinate(point utils.Point) int {
 	}
 
 	if tied {
-		return -1
+		return Tie
 	}
 
 	return closestIndex
@@ -1,8 +1,14 @@
 package day06
 
 import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math/rand"
 	"os"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 func readInput(t *testing.T) string {
@@ -83,3 +89,190 @@ func TestPart2(t *testing.T) {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
 }
+
+const example = `1, 1
+1, 6
+8, 3
+3, 4
+5, 5
+8, 9`
+
+func TestVoronoiExample(t *testing.T) {
+	v, err := New(example).Voronoi()
+	if err != nil {
+		t.Fatalf("Voronoi() error = %v", err)
+	}
+
+	// A, B, C and F reach the edge of the box; D and E are enclosed
+	for _, i := range []int{0, 1, 2, 5} {
+		witness, ok := v.Infinite[i]
+		if !ok {
+			t.Errorf("coordinate %d not reported infinite", i)
+			continue
+		}
+		onEdge := witness.X == v.Min.X || witness.X == v.Max.X || witness.Y == v.Min.Y || witness.Y == v.Max.Y
+		if !onEdge || v.Label(witness) != i {
+			t.Errorf("coordinate %d: witness %v is not an edge location it owns", i, witness)
+		}
+	}
+	if len(v.Infinite) != 4 {
+		t.Errorf("Infinite = %v, want 4 regions", v.Infinite)
+	}
+
+	if v.Areas[3] != 9 || v.Areas[4] != 17 {
+		t.Errorf("Areas = %v, want D = 9 and E = 17", v.Areas)
+	}
+	if index, area := v.LargestFiniteArea(); index != 4 || area != 17 {
+		t.Errorf("LargestFiniteArea() = %d, %d; want 4, 17", index, area)
+	}
+
+	// Row y=1 of the puzzle's map reads "aAaaa.cccc"
+	labels := map[utils.Point]int{{X: 1, Y: 1}: 0, {X: 4, Y: 1}: 0, {X: 5, Y: 1}: Tie, {X: 6, Y: 1}: 2}
+	for p, expected := range labels {
+		if label := v.Label(p); label != expected {
+			t.Errorf("Label(%v) = %d, want %d", p, label, expected)
+		}
+	}
+}
+
+func TestWritePNGExample(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(example).WritePNG(&buf, ImageOptions{Scale: 3, SafeDistance: 32}); err != nil {
+		t.Fatalf("WritePNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+
+	// The box spans x 1..8 and y 1..9
+	if bounds := img.Bounds(); bounds.Dx() != 8*3 || bounds.Dy() != 9*3 {
+		t.Fatalf("image size = %dx%d, want 24x27", bounds.Dx(), bounds.Dy())
+	}
+
+	pixel := func(p utils.Point) color.RGBA {
+		return color.RGBAModel.Convert(img.At((p.X-1)*3+1, (p.Y-1)*3+1)).(color.RGBA)
+	}
+	if c := pixel(utils.Point{X: 3, Y: 4}); c != (color.RGBA{A: 255}) {
+		t.Errorf("coordinate D drawn as %v, want black", c)
+	}
+	if c := pixel(utils.Point{X: 5, Y: 1}); c != (color.RGBA{R: 160, G: 160, B: 160, A: 255}) {
+		t.Errorf("tie at (5,1) drawn as %v, want grey", c)
+	}
+
+	// (4,3) is in D's region with a total distance of 30, so it is lightened;
+	// (6,6) is in E's region with a total distance of 32, so it is not
+	palette := regionColors(6)
+	d := palette[3]
+	if c := pixel(utils.Point{X: 4, Y: 3}); c != (color.RGBA{R: d.R/2 + 128, G: d.G/2 + 128, B: d.B/2 + 128, A: 255}) {
+		t.Errorf("safe location (4,3) drawn as %v, want D's colour lightened", c)
+	}
+	if c := pixel(utils.Point{X: 6, Y: 6}); c != palette[4] {
+		t.Errorf("location (6,6) drawn as %v, want E's colour %v", c, palette[4])
+	}
+}
+
+func TestNoCoordinates(t *testing.T) {
+	s := New("not a coordinate\n")
+	if _, err := s.Voronoi(); err == nil {
+		t.Errorf("Voronoi() succeeded, want an error")
+	}
+	if _, err := s.Part1(); err == nil {
+		t.Errorf("Part1() succeeded, want an error")
+	}
+	if err := s.WritePNG(&bytes.Buffer{}, ImageOptions{}); err == nil {
+		t.Errorf("WritePNG() succeeded, want an error")
+	}
+}
+
+func TestSingleCoordinate(t *testing.T) {
+	s := New("4, 7")
+	v, err := s.Voronoi()
+	if err != nil {
+		t.Fatalf("Voronoi() error = %v", err)
+	}
+
+	// The box is the coordinate itself, which is on every edge, so its region is infinite
+	if v.Min != (utils.Point{X: 4, Y: 7}) || v.Max != v.Min || v.Label(v.Min) != 0 || v.Areas[0] != 1 {
+		t.Errorf("Voronoi() = box %v..%v, areas %v, want the single location (4,7)", v.Min, v.Max, v.Areas)
+	}
+	if witness, ok := v.Infinite[0]; !ok || witness != v.Min {
+		t.Errorf("Infinite = %v, want coordinate 0 witnessed at (4,7)", v.Infinite)
+	}
+	if index, area := v.LargestFiniteArea(); index != -1 || area != 0 {
+		t.Errorf("LargestFiniteArea() = %d, %d; want -1, 0", index, area)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WritePNG(&buf, ImageOptions{SafeDistance: 5}); err != nil {
+		t.Fatalf("WritePNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 2 || bounds.Dy() != 2 {
+		t.Errorf("image size = %dx%d, want 2x2 at the default scale", bounds.Dx(), bounds.Dy())
+	}
+	if c := color.RGBAModel.Convert(img.At(1, 1)).(color.RGBA); c != (color.RGBA{A: 255}) {
+		t.Errorf("coordinate drawn as %v, want black over the safe region", c)
+	}
+}
+
+// scanRegionSize counts the region by checking every location that could be in it: a location
+// more than maxDistance/n outside the bounding box is that far from every coordinate.
+func scanRegionSize(s *Solution, maxDistance int) int {
+	pad := maxDistance / len(s.coordinates)
+	lo, hi := s.coordinates[0], s.coordinates[0]
+	for _, c := range s.coordinates {
+		lo = utils.Point{X: min(lo.X, c.X), Y: min(lo.Y, c.Y)}
+		hi = utils.Point{X: max(hi.X, c.X), Y: max(hi.Y, c.Y)}
+	}
+	count := 0
+	for x := lo.X - pad; x <= hi.X+pad; x++ {
+		for y := lo.Y - pad; y <= hi.Y+pad; y++ {
+			if s.totalDistance(utils.Point{X: x, Y: y}) < maxDistance {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestRegionSizeMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for trial := 0; trial < 50; trial++ {
+		coordinates := make([]utils.Point, 1+rng.Intn(8))
+		for i := range coordinates {
+			coordinates[i] = utils.Point{X: rng.Intn(30) - 10, Y: rng.Intn(30) - 10}
+		}
+		s := &Solution{coordinates: coordinates}
+		for _, maxDistance := range []int{0, 1, 25, 100, 400} {
+			if got, want := s.countRegionSize(maxDistance), scanRegionSize(s, maxDistance); got != want {
+				t.Errorf("%v: countRegionSize(%d) = %d, want %d", coordinates, maxDistance, got, want)
+			}
+		}
+	}
+}
+
+func TestRegionSizeSingleCoordinate(t *testing.T) {
+	// Around a single coordinate the region is a diamond of 2d²-2d+1 locations
+	const maxDistance = 1_000_000
+	s := New("3, -7")
+	if got, want := s.countRegionSize(maxDistance), 2*maxDistance*maxDistance-2*maxDistance+1; got != want {
+		t.Errorf("countRegionSize(%d) = %d, want %d", maxDistance, got, want)
+	}
+}
+
+// BenchmarkRegionSize measures a threshold whose region is hundreds of thousands of cells across.
+func BenchmarkRegionSize(b *testing.B) {
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		b.Fatalf("Failed to read input: %v", err)
+	}
+	s := New(string(input))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.countRegionSize(10_000_000)
+	}
+}
@@ -7,20 +7,34 @@
  *
  * Part 2: Simulate parallel execution with 5 workers. Each step takes 60 + letter_value seconds.
  * Multiple workers can work simultaneously on different steps. Track the total time to complete
- * all steps with proper scheduling and timing constraints.
+ * all steps with proper scheduling and timing constraints. Schedule keeps the whole run,
+ * which Table prints in the puzzle's layout and SVG draws as a Gantt chart.
  */
 
 package day07
 
 import (
+	"fmt"
+	"html"
 	"sort"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
+)
+
+// Default Part 2 parameters from the puzzle
+const (
+	defaultWorkers  = 5
+	defaultBaseTime = 60
 )
 
 type Solution struct {
-	dependencies  map[string][]string // step -> list of steps that depend on it
-	prerequisites map[string][]string // step -> list of prerequisites
-	allSteps      []string
+	dependencies map[string][]string // step -> list of steps that depend on it
+	allSteps     []string
+
+	// Workers and BaseTime configure the Part 2 simulation
+	Workers  int
+	BaseTime int
 }
 
 func New(input string) *Solution {
@@ -29,7 +43,6 @@ func New(input string) *Solution {
 	lines := strings.Split(input, "\n")
 
 	dependencies := make(map[string][]string)
-	prerequisites := make(map[string][]string)
 	stepSet := make(map[string]bool)
 
 	for _, line := range lines {
@@ -39,7 +52,6 @@ func New(input string) *Solution {
 		dependent := parts[7]
 
 		dependencies[prerequisite] = append(dependencies[prerequisite], dependent)
-		prerequisites[dependent] = append(prerequisites[dependent], prerequisite)
 
 		stepSet[prerequisite] = true
 		stepSet[dependent] = true
@@ -53,159 +65,162 @@ func New(input string) *Solution {
 	sort.Strings(allSteps)
 
 	return &Solution{
-		dependencies:  dependencies,
-		prerequisites: prerequisites,
-		allSteps:      allSteps,
+		dependencies: dependencies,
+		allSteps:     allSteps,
+		Workers:      defaultWorkers,
+		BaseTime:     defaultBaseTime,
 	}
 }
 
 func (s *Solution) Part1() (string, error) {
-	return s.topologicalSort(), nil
+	// A single worker with unit-time steps completes them in topological order
+	schedule, err := utils.ScheduleTasks(s.allSteps, s.dependencies, utils.ScheduleOptions{})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(schedule.Order, ""), nil
 }
 
 func (s *Solution) Part2() (int, error) {
-	return s.simulateWithWorkers(5, 60), nil
+	schedule, err := s.Schedule(s.Workers, s.BaseTime)
+	if err != nil {
+		return 0, err
+	}
+	return schedule.Duration, nil
 }
 
-// topologicalSort performs Kahn's algorithm with alphabetical tiebreaking
-func (s *Solution) topologicalSort() string {
-	// Copy prerequisites map to avoid modifying original
-	remainingPrereqs := make(map[string][]string)
-	for step, prereqs := range s.prerequisites {
-		remainingPrereqs[step] = make([]string, len(prereqs))
-		copy(remainingPrereqs[step], prereqs)
-	}
+// Schedule is a simulated run of the workers, which can be drawn as the
+// puzzle's table or as a Gantt chart.
+type Schedule struct {
+	*utils.Schedule
+}
 
-	var result []string
-	var available []string
+// Schedule simulates the given number of workers completing all steps,
+// where each step takes baseTime plus its letter value (A=1, B=2, ...) seconds.
+// Available steps are picked alphabetically.
+func (s *Solution) Schedule(numWorkers, baseTime int) (*Schedule, error) {
+	schedule, err := utils.ScheduleTasks(s.allSteps, s.dependencies, utils.ScheduleOptions{
+		Workers: numWorkers,
+		Duration: func(step string) int {
+			return baseTime + int(step[0]-'A'+1)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Schedule{schedule}, nil
+}
 
-	// Find initial steps with no prerequisites
-	for _, step := range s.allSteps {
-		if len(remainingPrereqs[step]) == 0 {
-			available = append(available, step)
-		}
+// Table renders the schedule one row per second, in the layout of the puzzle's
+// example: the second, the step each worker is doing (or "." when idle), and the
+// steps completed so far. Columns line up for up to nine workers.
+func (s *Schedule) Table() string {
+	var sb strings.Builder
+
+	// Worker cells sit three characters into their header
+	header := "Second"
+	cellCols := make([]int, s.Workers)
+	for w := 0; w < s.Workers; w++ {
+		header += "   "
+		cellCols[w] = len(header) + 3
+		header += fmt.Sprintf("Worker %d", w+1)
 	}
+	header += "   "
+	doneCol := len(header)
+	header += "Done"
+	sb.WriteString(header)
+
+	byEnd := make([]utils.Assignment, len(s.Assignments))
+	copy(byEnd, s.Assignments)
+	sort.SliceStable(byEnd, func(i, j int) bool {
+		if byEnd[i].End != byEnd[j].End {
+			return byEnd[i].End < byEnd[j].End
+		}
+		return byEnd[i].Worker < byEnd[j].Worker
+	})
+
+	done := ""
+	finished := 0
+	for second := 0; second <= s.Duration; second++ {
+		for finished < len(byEnd) && byEnd[finished].End <= second {
+			done += byEnd[finished].Task
+			finished++
+		}
 
-	for len(available) > 0 {
-		// Sort available steps alphabetically and pick the first one
-		sort.Strings(available)
-		current := available[0]
-		available = available[1:]
-
-		result = append(result, current)
-
-		// Remove current step from prerequisites of dependent steps
-		for _, dependent := range s.dependencies[current] {
-			// Remove current from dependent's prerequisites
-			newPrereqs := make([]string, 0)
-			for _, prereq := range remainingPrereqs[dependent] {
-				if prereq != current {
-					newPrereqs = append(newPrereqs, prereq)
-				}
+		cells := make([]string, s.Workers)
+		for w := range cells {
+			cells[w] = "."
+		}
+		for _, a := range s.Assignments {
+			if a.Start <= second && second < a.End {
+				cells[a.Worker] = a.Task
 			}
-			remainingPrereqs[dependent] = newPrereqs
+		}
 
-			// If dependent has no more prerequisites, add it to available
-			if len(remainingPrereqs[dependent]) == 0 {
-				available = append(available, dependent)
-			}
+		row := fmt.Sprintf("%4d", second)
+		for w, cell := range cells {
+			row += strings.Repeat(" ", max(1, cellCols[w]-len(row))) + cell
+		}
+		row += strings.Repeat(" ", max(1, doneCol-len(row))) + done
+		if done == "" {
+			// The puzzle's rows keep one space under Done before any step is done
+			row += " "
 		}
-	}
 
-	return strings.Join(result, "")
-}
+		sb.WriteString("\n")
+		sb.WriteString(row)
+	}
 
-// Worker represents a worker with their current task and completion time
-type Worker struct {
-	currentStep string
-	finishTime  int
+	return sb.String()
 }
 
-// simulateWithWorkers simulates parallel execution with the given number of workers
-func (s *Solution) simulateWithWorkers(numWorkers, baseTime int) int {
-	// Copy prerequisites map to avoid modifying original
-	remainingPrereqs := make(map[string][]string)
-	for step, prereqs := range s.prerequisites {
-		remainingPrereqs[step] = make([]string, len(prereqs))
-		copy(remainingPrereqs[step], prereqs)
+// SVG renders the schedule as a Gantt chart with one lane per worker
+// and one labelled bar per step.
+func (s *Schedule) SVG() string {
+	const (
+		labelWidth = 80
+		chartWidth = 960
+		laneHeight = 30
+		barPadding = 4
+		axisHeight = 24
+	)
+
+	duration := max(s.Duration, 1)
+	scale := float64(chartWidth) / float64(duration)
+	width := labelWidth + chartWidth + 20
+	height := s.Workers*laneHeight + axisHeight
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", width, height)
+
+	// Worker lanes
+	for w := 0; w < s.Workers; w++ {
+		y := w * laneHeight
+		fmt.Fprintf(&sb, `  <text x="4" y="%d" dominant-baseline="middle">Worker %d</text>`+"\n", y+laneHeight/2, w+1)
+		fmt.Fprintf(&sb, `  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#ddd"/>`+"\n", labelWidth, y+laneHeight, labelWidth+chartWidth, y+laneHeight)
 	}
 
-	workers := make([]Worker, numWorkers)
-	var available []string
-	completed := make(map[string]bool)
-	currentTime := 0
-
-	// Find initial steps with no prerequisites
-	for _, step := range s.allSteps {
-		if len(remainingPrereqs[step]) == 0 {
-			available = append(available, step)
-		}
+	// Task bars
+	for _, a := range s.Assignments {
+		x := float64(labelWidth) + float64(a.Start)*scale
+		w := float64(a.End-a.Start) * scale
+		y := a.Worker*laneHeight + barPadding
+		task := html.EscapeString(a.Task)
+		fmt.Fprintf(&sb, `  <rect x="%.2f" y="%d" width="%.2f" height="%d" fill="#4a90d9" stroke="#1f4e79"><title>%s: %d-%d</title></rect>`+"\n",
+			x, y, w, laneHeight-2*barPadding, task, a.Start, a.End)
+		fmt.Fprintf(&sb, `  <text x="%.2f" y="%d" fill="#fff" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			x+w/2, a.Worker*laneHeight+laneHeight/2, task)
 	}
 
-	for len(completed) < len(s.allSteps) {
-		// Check for completed work and free up workers
-		for i := range workers {
-			if workers[i].currentStep != "" && currentTime >= workers[i].finishTime {
-				completedStep := workers[i].currentStep
-				completed[completedStep] = true
-				workers[i].currentStep = ""
-				workers[i].finishTime = 0
-
-				// Remove completed step from prerequisites of dependent steps
-				for _, dependent := range s.dependencies[completedStep] {
-					if !completed[dependent] {
-						newPrereqs := make([]string, 0)
-						for _, prereq := range remainingPrereqs[dependent] {
-							if prereq != completedStep {
-								newPrereqs = append(newPrereqs, prereq)
-							}
-						}
-						remainingPrereqs[dependent] = newPrereqs
-
-						// If dependent has no more prerequisites, add it to available
-						if len(remainingPrereqs[dependent]) == 0 {
-							available = append(available, dependent)
-						}
-					}
-				}
-			}
-		}
-
-		// Sort available steps alphabetically
-		sort.Strings(available)
-
-		// Assign work to available workers
-		for i := range workers {
-			if workers[i].currentStep == "" && len(available) > 0 {
-				step := available[0]
-				available = available[1:]
-
-				workers[i].currentStep = step
-				workers[i].finishTime = currentTime + baseTime + int(step[0]-'A'+1)
-			}
-		}
-
-		// Find the next time when a worker will finish
-		nextFinishTime := -1
-		for i := range workers {
-			if workers[i].currentStep != "" {
-				if nextFinishTime == -1 || workers[i].finishTime < nextFinishTime {
-					nextFinishTime = workers[i].finishTime
-				}
-			}
-		}
-
-		if nextFinishTime != -1 {
-			currentTime = nextFinishTime
-		} else {
-			// No one is working, but if there are still available steps, assign them
-			if len(available) > 0 {
-				currentTime++
-			} else {
-				break
-			}
-		}
+	// Time axis with roughly ten ticks
+	axisY := s.Workers * laneHeight
+	step := max(1, duration/10)
+	for t := 0; t <= duration; t += step {
+		x := float64(labelWidth) + float64(t)*scale
+		fmt.Fprintf(&sb, `  <line x1="%.2f" y1="0" x2="%.2f" y2="%d" stroke="#999" stroke-dasharray="2,2"/>`+"\n", x, x, axisY)
+		fmt.Fprintf(&sb, `  <text x="%.2f" y="%d" text-anchor="middle">%d</text>`+"\n", x, axisY+16, t)
 	}
 
-	return currentTime
+	sb.WriteString("</svg>\n")
+	return sb.String()
 }
@@ -2,7 +2,11 @@ package day07
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 func readInput(t *testing.T) string {
@@ -66,10 +70,122 @@ Step F must be finished before step E can begin.`
 	solution := New(input)
 
 	// Test with example parameters: 2 workers, 0 base time (so A=1, F=6, etc.)
-	result := solution.simulateWithWorkers(2, 0)
+	solution.Workers = 2
+	solution.BaseTime = 0
+	result, err := solution.Part2()
+
+	if err != nil {
+		t.Errorf("Part2() error = %v", err)
+		return
+	}
+
 	expected := 15
 	if result != expected {
-		t.Errorf("simulateWithWorkers(2, 0) = %v, want %v", result, expected)
+		t.Errorf("Part2() = %v, want %v", result, expected)
+	}
+}
+
+func TestScheduleExample(t *testing.T) {
+	input := `Step C must be finished before step A can begin.
+Step C must be finished before step F can begin.
+Step A must be finished before step B can begin.
+Step A must be finished before step D can begin.
+Step B must be finished before step E can begin.
+Step D must be finished before step E can begin.
+Step F must be finished before step E can begin.`
+
+	schedule, err := New(input).Schedule(2, 0)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+
+	// Timeline from the puzzle's worked example
+	expected := []utils.Assignment{
+		{Task: "C", Worker: 0, Start: 0, End: 3},
+		{Task: "A", Worker: 0, Start: 3, End: 4},
+		{Task: "F", Worker: 1, Start: 3, End: 9},
+		{Task: "B", Worker: 0, Start: 4, End: 6},
+		{Task: "D", Worker: 0, Start: 6, End: 10},
+		{Task: "E", Worker: 0, Start: 10, End: 15},
+	}
+	if !reflect.DeepEqual(schedule.Assignments, expected) {
+		t.Errorf("Schedule() assignments = %+v, want %+v", schedule.Assignments, expected)
+	}
+}
+
+func TestScheduleTableExample(t *testing.T) {
+	input := `Step C must be finished before step A can begin.
+Step C must be finished before step F can begin.
+Step A must be finished before step B can begin.
+Step A must be finished before step D can begin.
+Step B must be finished before step E can begin.
+Step D must be finished before step E can begin.
+Step F must be finished before step E can begin.`
+
+	schedule, err := New(input).Schedule(2, 0)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+
+	// Table from the puzzle text, including its trailing spaces
+	expected := strings.Join([]string{
+		"Second   Worker 1   Worker 2   Done",
+		"   0        C          .        ",
+		"   1        C          .        ",
+		"   2        C          .        ",
+		"   3        A          F       C",
+		"   4        B          F       CA",
+		"   5        B          F       CA",
+		"   6        D          F       CAB",
+		"   7        D          F       CAB",
+		"   8        D          F       CAB",
+		"   9        D          .       CABF",
+		"  10        E          .       CABFD",
+		"  11        E          .       CABFD",
+		"  12        E          .       CABFD",
+		"  13        E          .       CABFD",
+		"  14        E          .       CABFD",
+		"  15        .          .       CABFDE",
+	}, "\n")
+
+	if table := schedule.Table(); table != expected {
+		t.Errorf("Table() =\n%s\nwant\n%s", table, expected)
+	}
+}
+
+func TestScheduleSVGExample(t *testing.T) {
+	input := `Step C must be finished before step A can begin.
+Step C must be finished before step F can begin.
+Step A must be finished before step B can begin.
+Step A must be finished before step D can begin.
+Step B must be finished before step E can begin.
+Step D must be finished before step E can begin.
+Step F must be finished before step E can begin.`
+
+	schedule, err := New(input).Schedule(2, 0)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	svg := schedule.SVG()
+
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("SVG() is not a single svg element:\n%s", svg)
+	}
+	if strings.Count(svg, "<rect") != len(schedule.Assignments) {
+		t.Errorf("SVG() has %d bars, want one per step (%d)", strings.Count(svg, "<rect"), len(schedule.Assignments))
+	}
+
+	// 15 seconds over 960 pixels after the 80-pixel labels: F runs 3-9 in the second lane
+	for _, want := range []string{
+		`>Worker 1</text>`,
+		`>Worker 2</text>`,
+		`<rect x="272.00" y="34" width="384.00" height="22" fill="#4a90d9" stroke="#1f4e79"><title>F: 3-9</title></rect>`,
+		`<title>E: 10-15</title>`,
+		`>15</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG() is missing %s", want)
+		}
 	}
 }
 
@@ -3,7 +3,7 @@
  *
  * Part 1: Parse a tree structure from a flat list of numbers and sum all metadata entries.
  * Each node has a header with child count and metadata count, followed by children and metadata.
- * The tree is encoded in a specific format that needs recursive parsing.
+ * The tree is parsed with an explicit stack, so malformed input is reported by position.
  *
  * Part 2: Calculate the value of nodes based on specific rules involving metadata as indices.
  * Nodes with no children have value equal to sum of metadata. Nodes with children use
@@ -13,106 +13,261 @@
 package day08
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
 
+// Node is one node of the license tree.
 type Node struct {
-	children []Node
-	metadata []int
+	Children []*Node
+	Metadata []int
 }
 
-type Solution struct {
-	numbers []int
+// ParseError reports where the number stream stopped describing a valid tree.
+// Index is the 0-based position of the offending number in the stream.
+type ParseError struct {
+	Index int
+	Msg   string
 }
 
-func New(input string) *Solution {
-	input = strings.ReplaceAll(strings.TrimSpace(input), "\r\n", "\n")
-	fields := strings.Fields(input)
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("number %d: %s", e.Index, e.Msg)
+}
 
-	numbers := make([]int, 0, len(fields))
-	for _, field := range fields {
-		num, err := strconv.Atoi(field)
-		if err != nil {
-			// Skip invalid numbers - this shouldn't happen with valid puzzle input
-			continue
-		}
-		numbers = append(numbers, num)
-	}
+type Solution struct {
+	root *Node
+	err  error
+}
 
-	return &Solution{numbers: numbers}
+func New(input string) *Solution {
+	root, err := ParseTreeString(input)
+	return &Solution{root: root, err: err}
 }
 
 func (s *Solution) Part1() (int, error) {
-	root, _ := s.parseNode(0)
-	return s.sumMetadata(root), nil
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.root.MetadataSum(), nil
 }
 
 func (s *Solution) Part2() (int, error) {
-	root, _ := s.parseNode(0)
-	return s.nodeValue(root), nil
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.root.Value(), nil
 }
 
-// parseNode parses a node starting at the given index and returns the node and next index
-func (s *Solution) parseNode(index int) (Node, int) {
-	childCount := s.numbers[index]
-	metadataCount := s.numbers[index+1]
-	index += 2
+// ParseTreeString parses a whitespace-separated number stream into a tree.
+func ParseTreeString(input string) (*Node, error) {
+	fields := strings.Fields(input)
+	numbers := make([]int, len(fields))
+	for i, field := range fields {
+		num, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, &ParseError{Index: i, Msg: fmt.Sprintf("%q is not a number", field)}
+		}
+		numbers[i] = num
+	}
+	return ParseTree(numbers)
+}
 
-	var node Node
-	node.children = make([]Node, childCount)
+// ParseTree parses a number stream into a tree. It walks the stream with an explicit
+// stack rather than recursion, so arbitrarily deep trees are fine, and reports
+// truncated or malformed input as a *ParseError.
+func ParseTree(numbers []int) (*Node, error) {
+	type frame struct {
+		node          *Node
+		start         int // index of the node's header
+		childrenLeft  int
+		metadataCount int
+	}
 
-	// Parse children
-	for i := 0; i < childCount; i++ {
-		child, nextIndex := s.parseNode(index)
-		node.children[i] = child
-		index = nextIndex
+	pos := 0
+	readHeader := func() (*frame, error) {
+		if pos+2 > len(numbers) {
+			return nil, &ParseError{Index: pos, Msg: "truncated header: expected child and metadata counts"}
+		}
+		childCount, metadataCount := numbers[pos], numbers[pos+1]
+		if childCount < 0 {
+			return nil, &ParseError{Index: pos, Msg: fmt.Sprintf("negative child count %d", childCount)}
+		}
+		if metadataCount < 0 {
+			return nil, &ParseError{Index: pos + 1, Msg: fmt.Sprintf("negative metadata count %d", metadataCount)}
+		}
+		// Each child takes at least two numbers, so a count the stream can't hold is malformed
+		if childCount > (len(numbers)-pos)/2 {
+			return nil, &ParseError{Index: pos, Msg: fmt.Sprintf("child count %d exceeds the remaining input", childCount)}
+		}
+		f := &frame{
+			node:          &Node{Children: make([]*Node, 0, childCount)},
+			start:         pos,
+			childrenLeft:  childCount,
+			metadataCount: metadataCount,
+		}
+		pos += 2
+		return f, nil
 	}
 
-	// Parse metadata
-	node.metadata = make([]int, metadataCount)
-	for i := 0; i < metadataCount; i++ {
-		node.metadata[i] = s.numbers[index]
-		index++
+	root, err := readHeader()
+	if err != nil {
+		return nil, err
 	}
+	stack := []*frame{root}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
 
-	return node, index
-}
+		// Parse the next child
+		if top.childrenLeft > 0 {
+			top.childrenLeft--
+			child, err := readHeader()
+			if err != nil {
+				return nil, err
+			}
+			top.node.Children = append(top.node.Children, child.node)
+			stack = append(stack, child)
+			continue
+		}
 
-// sumMetadata recursively sums all metadata in the tree
-func (s *Solution) sumMetadata(node Node) int {
-	sum := 0
+		// All children done; parse metadata
+		if remaining := len(numbers) - pos; top.metadataCount > remaining {
+			return nil, &ParseError{Index: pos, Msg: fmt.Sprintf("node at number %d needs %d metadata entries, only %d remain", top.start, top.metadataCount, remaining)}
+		}
+		top.node.Metadata = append([]int(nil), numbers[pos:pos+top.metadataCount]...)
+		pos += top.metadataCount
+		stack = stack[:len(stack)-1]
+	}
+
+	if pos != len(numbers) {
+		return nil, &ParseError{Index: pos, Msg: fmt.Sprintf("%d numbers left over after the root node", len(numbers)-pos)}
+	}
+	return root.node, nil
+}
 
-	// Add this node's metadata
-	for _, meta := range node.metadata {
-		sum += meta
+// Encode returns the number stream the tree was parsed from.
+func (n *Node) Encode() []int {
+	var numbers []int
+	type frame struct {
+		node *Node
+		next int // next child to encode
 	}
+	stack := []frame{{node: n}}
+	numbers = append(numbers, len(n.Children), len(n.Metadata))
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			numbers = append(numbers, len(child.Children), len(child.Metadata))
+			stack = append(stack, frame{node: child})
+			continue
+		}
+		numbers = append(numbers, top.node.Metadata...)
+		stack = stack[:len(stack)-1]
+	}
+	return numbers
+}
 
-	// Add children's metadata
-	for _, child := range node.children {
-		sum += s.sumMetadata(child)
+// String returns the encoded tree as space-separated numbers, as in the puzzle input.
+func (n *Node) String() string {
+	numbers := n.Encode()
+	fields := make([]string, len(numbers))
+	for i, num := range numbers {
+		fields[i] = strconv.Itoa(num)
 	}
+	return strings.Join(fields, " ")
+}
 
+// MetadataSum returns the sum of every metadata entry in the tree.
+func (n *Node) MetadataSum() int {
+	sum := 0
+	for _, node := range n.postOrder() {
+		for _, meta := range node.Metadata {
+			sum += meta
+		}
+	}
 	return sum
 }
 
-// nodeValue calculates the value of a node according to Part 2 rules
-func (s *Solution) nodeValue(node Node) int {
-	if len(node.children) == 0 {
-		// No children: value is sum of metadata
+// Value returns the node's value according to Part 2 rules.
+func (n *Node) Value() int {
+	return n.values()[n]
+}
+
+// values computes the value of every node in the tree, children before parents.
+func (n *Node) values() map[*Node]int {
+	values := make(map[*Node]int)
+	for _, node := range n.postOrder() {
 		sum := 0
-		for _, meta := range node.metadata {
-			sum += meta
+		for _, meta := range node.Metadata {
+			if len(node.Children) == 0 {
+				// No children: value is sum of metadata
+				sum += meta
+			} else if meta >= 1 && meta <= len(node.Children) {
+				// Has children: metadata are 1-indexed references to children
+				sum += values[node.Children[meta-1]]
+			}
 		}
-		return sum
+		values[node] = sum
 	}
+	return values
+}
 
-	// Has children: metadata are 1-indexed references to children
-	sum := 0
-	for _, meta := range node.metadata {
-		if meta >= 1 && meta <= len(node.children) {
-			sum += s.nodeValue(node.children[meta-1])
+// postOrder lists the tree's nodes with every child before its parent.
+func (n *Node) postOrder() []*Node {
+	var order []*Node
+	stack := []*Node{n}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		order = append(order, node)
+		stack = append(stack, node.Children...)
+	}
+	// Reversing a parent-first walk puts every child before its parent
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// DOT renders the tree as a Graphviz digraph. Nodes are numbered in input order and
+// labelled with their value and metadata; edges carry the 1-based child index that
+// metadata entries refer to.
+func (n *Node) DOT() string {
+	values := n.values()
+
+	var sb strings.Builder
+	sb.WriteString("digraph license {\n")
+	sb.WriteString("  node [shape=box, fontname=monospace];\n")
+
+	ids := make(map[*Node]int)
+	var visit []*Node
+	stack := []*Node{n}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		ids[node] = len(ids)
+		visit = append(visit, node)
+		// Push children in reverse so they are numbered left to right
+		for i := len(node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, node.Children[i])
 		}
 	}
-	return sum
+
+	for _, node := range visit {
+		metadata := make([]string, len(node.Metadata))
+		for i, meta := range node.Metadata {
+			metadata[i] = strconv.Itoa(meta)
+		}
+		fmt.Fprintf(&sb, "  n%d [label=\"value %d\\nmetadata %s\"];\n", ids[node], values[node], strings.Join(metadata, " "))
+	}
+	for _, node := range visit {
+		for i, child := range node.Children {
+			fmt.Fprintf(&sb, "  n%d -> n%d [label=\"%d\"];\n", ids[node], ids[child], i+1)
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
 }
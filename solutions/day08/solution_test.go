@@ -1,7 +1,9 @@
 package day08
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +83,98 @@ func TestPart2(t *testing.T) {
 		t.Logf("Part2() = %v", result)
 	}
 }
+
+func TestRoundTrip(t *testing.T) {
+	input := strings.Join(strings.Fields(readInput(t)), " ")
+
+	root, err := ParseTreeString(input)
+	if err != nil {
+		t.Fatalf("ParseTreeString() error = %v", err)
+	}
+	if encoded := root.String(); encoded != input {
+		t.Errorf("String() does not reproduce the input: got %d characters, want %d", len(encoded), len(input))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		index int
+	}{
+		{"empty", "", 0},
+		{"truncated header", "1 1 0", 2},
+		{"truncated metadata", "2 3 0 3 10 11 12 1 1 0 1 99 2 1 1", 13},
+		{"missing child", "2 1 0 1 5", 5},
+		{"negative metadata count", "0 -1", 1},
+		{"not a number", "0 2 1 x", 3},
+		{"trailing numbers", "0 1 5 7 8", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTreeString(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("ParseTreeString(%q) error = %v, want a *ParseError", tt.input, err)
+			}
+			if parseErr.Index != tt.index {
+				t.Errorf("ParseTreeString(%q) error at index %d, want %d (%v)", tt.input, parseErr.Index, tt.index, err)
+			}
+
+			// Part 1 reports the same error instead of panicking
+			if _, err := New(tt.input).Part1(); !errors.As(err, &parseErr) {
+				t.Errorf("Part1() error = %v, want a *ParseError", err)
+			}
+		})
+	}
+}
+
+func TestDeepTree(t *testing.T) {
+	// A chain 200,000 nodes deep, each with one child and one metadata entry of 1
+	const depth = 200_000
+	numbers := make([]int, 0, 3*depth)
+	for i := 0; i < depth-1; i++ {
+		numbers = append(numbers, 1, 1)
+	}
+	numbers = append(numbers, 0, 1, 7)
+	for i := 0; i < depth-1; i++ {
+		numbers = append(numbers, 1)
+	}
+
+	root, err := ParseTree(numbers)
+	if err != nil {
+		t.Fatalf("ParseTree() error = %v", err)
+	}
+	if sum := root.MetadataSum(); sum != 7+depth-1 {
+		t.Errorf("MetadataSum() = %d, want %d", sum, 7+depth-1)
+	}
+	if value := root.Value(); value != 7 {
+		t.Errorf("Value() = %d, want 7", value)
+	}
+	if len(root.Encode()) != len(numbers) {
+		t.Errorf("Encode() returned %d numbers, want %d", len(root.Encode()), len(numbers))
+	}
+}
+
+func TestDOTExample(t *testing.T) {
+	root, err := ParseTreeString("2 3 0 3 10 11 12 1 1 0 1 99 2 1 1 2")
+	if err != nil {
+		t.Fatalf("ParseTreeString() error = %v", err)
+	}
+
+	expected := `digraph license {
+  node [shape=box, fontname=monospace];
+  n0 [label="value 66\nmetadata 1 1 2"];
+  n1 [label="value 33\nmetadata 10 11 12"];
+  n2 [label="value 0\nmetadata 2"];
+  n3 [label="value 99\nmetadata 99"];
+  n0 -> n1 [label="1"];
+  n0 -> n2 [label="2"];
+  n2 -> n3 [label="1"];
+}
+`
+	if dot := root.DOT(); dot != expected {
+		t.Errorf("DOT() =\n%s\nwant\n%s", dot, expected)
+	}
+}
@@ -7,23 +7,22 @@
  * remove a marble 7 positions counter-clockwise.
  *
  * Part 2: Same game but with 100 times more marbles, requiring efficient data structure.
- * Uses circular doubly-linked list for O(1) insertion and removal operations.
+ * Uses a ring-buffer deque with the current marble at the back, so rotating and
+ * placing or removing marbles are all O(1) without allocating per marble.
+ * Game plays one marble per Step, keeps every player's score, and prints the circle
+ * in the puzzle's trace format.
  */
 
 package day09
 
 import (
 	"fmt"
+	"iter"
 	"strconv"
 	"strings"
-)
 
-// Marble represents a node in our circular doubly-linked list
-type Marble struct {
-	value int
-	next  *Marble
-	prev  *Marble
-}
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
+)
 
 type Solution struct {
 	players    int
@@ -65,65 +64,156 @@ func (s *Solution) Part1() (int, error) {
 	if s.players <= 0 || s.lastMarble <= 0 {
 		return 0, fmt.Errorf("invalid input: players=%d, lastMarble=%d must be positive", s.players, s.lastMarble)
 	}
-	return s.playGame(s.lastMarble), nil
+	return s.playGame(s.lastMarble)
 }
 
 func (s *Solution) Part2() (int, error) {
 	if s.players <= 0 || s.lastMarble <= 0 {
 		return 0, fmt.Errorf("invalid input: players=%d, lastMarble=%d must be positive", s.players, s.lastMarble)
 	}
-	return s.playGame(s.lastMarble * 100), nil
+	return s.playGame(s.lastMarble * 100)
 }
 
 // playGame simulates the marble game and returns the highest score
-func (s *Solution) playGame(maxMarble int) int {
-	scores := make([]int, s.players)
+func (s *Solution) playGame(maxMarble int) (int, error) {
+	game, err := NewGame(s.players, maxMarble)
+	if err != nil {
+		return 0, err
+	}
+	return game.Play().HighScore(), nil
+}
 
-	// Create initial marble 0
-	current := &Marble{value: 0}
-	current.next = current
-	current.prev = current
+// Game is a marble game that can be played to the end or one marble at a time.
+type Game struct {
+	players    int
+	lastMarble int
+	marble     int // the last marble played, 0 before the first turn
+	scores     []int
 
-	for marble := 1; marble <= maxMarble; marble++ {
-		player := (marble - 1) % s.players
+	// The circle is a deque with the current marble at the back;
+	// clockwise from the current marble wraps around to the front
+	circle *utils.Deque[int]
+}
 
-		if marble%23 == 0 {
-			// Special case: marble divisible by 23
-			scores[player] += marble
+// NewGame sets up a game for the given number of players, ending once lastMarble has been played.
+// It returns an error unless there is at least one player and lastMarble is not negative.
+func NewGame(players, lastMarble int) (*Game, error) {
+	if players <= 0 || lastMarble < 0 {
+		return nil, fmt.Errorf("invalid game: players=%d must be positive and lastMarble=%d not negative", players, lastMarble)
+	}
+	circle := utils.NewDeque[int](lastMarble + 1)
+	circle.PushBack(0)
+	return &Game{
+		players:    players,
+		lastMarble: lastMarble,
+		scores:     make([]int, players),
+		circle:     circle,
+	}, nil
+}
 
-			// Move 7 positions counter-clockwise
-			for i := 0; i < 7; i++ {
-				current = current.prev
-			}
+// Step plays the next marble and reports whether there was one left to play.
+func (g *Game) Step() bool {
+	if g.marble >= g.lastMarble {
+		return false
+	}
+	g.marble++
+	marble := g.marble
+
+	if marble%23 == 0 {
+		// Special case: marble divisible by 23
+		player := (marble - 1) % g.players
+		g.scores[player] += marble
+
+		// Move 7 positions counter-clockwise and remove that marble
+		g.circle.Rotate(-7)
+		g.scores[player] += g.circle.PopBack()
+
+		// The marble clockwise of the removed one becomes current
+		g.circle.Rotate(1)
+	} else {
+		// Normal case: place marble between 1 and 2 clockwise
+		g.circle.Rotate(1)
+		g.circle.PushBack(marble)
+	}
+	return true
+}
 
-			// Add the marble being removed to score
-			scores[player] += current.value
+// Play plays every remaining marble and returns the game.
+func (g *Game) Play() *Game {
+	for g.Step() {
+	}
+	return g
+}
 
-			// Remove the marble and update current
-			current.prev.next = current.next
-			current.next.prev = current.prev
-			current = current.next
-		} else {
-			// Normal case: place marble between 1 and 2 clockwise
-			current = current.next
-			newMarble := &Marble{
-				value: marble,
-				next:  current.next,
-				prev:  current,
+// Steps returns an iterator over the game's trace: the starting circle, then the circle after each
+// marble, in the format of String. It plays the game as it goes.
+func (g *Game) Steps() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if !yield(g.String()) {
+			return
+		}
+		for g.Step() {
+			if !yield(g.String()) {
+				return
 			}
-			current.next.prev = newMarble
-			current.next = newMarble
-			current = newMarble
 		}
 	}
+}
+
+// Scores returns each player's score so far, indexed from the first player.
+func (g *Game) Scores() []int {
+	return append([]int(nil), g.scores...)
+}
 
-	// Find maximum score
+// HighScore returns the best score so far.
+func (g *Game) HighScore() int {
 	maxScore := 0
-	for _, score := range scores {
-		if score > maxScore {
-			maxScore = score
-		}
+	for _, score := range g.scores {
+		maxScore = max(maxScore, score)
 	}
-
 	return maxScore
 }
+
+// String draws the circle the way the puzzle does, e.g. "[3]  0  2  1 (3)": the player who
+// placed the last marble, then every marble clockwise from the lowest, which is 0 until a
+// long game scores it, with the current one in brackets.
+func (g *Game) String() string {
+	var b strings.Builder
+	if g.marble == 0 {
+		b.WriteString("[-]")
+	} else {
+		fmt.Fprintf(&b, "[%d]", (g.marble-1)%g.players+1)
+	}
+
+	n := g.circle.Len()
+	start := 0
+	for i := 1; i < n; i++ {
+		if g.circle.At(i) < g.circle.At(start) {
+			start = i
+		}
+	}
+	afterCurrent := false
+	for i := 0; i < n; i++ {
+		index := (start + i) % n
+		value := g.circle.At(index)
+		if index == n-1 {
+			// The opening bracket takes the place of the separator, the closing one the next
+			fmt.Fprintf(&b, "%3s", "("+strconv.Itoa(value))
+			afterCurrent = true
+			continue
+		}
+		if afterCurrent {
+			b.WriteByte(')')
+			afterCurrent = false
+		} else {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%2d", value)
+	}
+	if afterCurrent {
+		b.WriteByte(')')
+	} else {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
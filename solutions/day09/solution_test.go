@@ -2,7 +2,11 @@ package day09
 
 import (
 	"os"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 func readInput(t *testing.T) string {
@@ -77,3 +81,172 @@ func TestPart2(t *testing.T) {
 		t.Logf("Part2() = %v", result)
 	}
 }
+
+// marble is a node of the circular doubly-linked list the game used to be played on.
+type marble struct {
+	value      int
+	next, prev *marble
+}
+
+// playGameLinkedList is the previous linked-list implementation, kept as a benchmark baseline.
+func playGameLinkedList(players, maxMarble int) int {
+	scores := make([]int, players)
+	current := &marble{value: 0}
+	current.next = current
+	current.prev = current
+
+	for m := 1; m <= maxMarble; m++ {
+		player := (m - 1) % players
+		if m%23 == 0 {
+			scores[player] += m
+			for i := 0; i < 7; i++ {
+				current = current.prev
+			}
+			scores[player] += current.value
+			current.prev.next = current.next
+			current.next.prev = current.prev
+			current = current.next
+		} else {
+			current = current.next
+			newMarble := &marble{value: m, next: current.next, prev: current}
+			current.next.prev = newMarble
+			current.next = newMarble
+			current = newMarble
+		}
+	}
+
+	maxScore := 0
+	for _, score := range scores {
+		maxScore = max(maxScore, score)
+	}
+	return maxScore
+}
+
+// BenchmarkPart2 compares the deque against the linked list on the Part 2 game.
+// Run with -benchmem to see the per-marble allocations disappear.
+func BenchmarkPart2(b *testing.B) {
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		b.Fatalf("Failed to read input: %v", err)
+	}
+	solution := New(string(input))
+
+	b.Run("LinkedList", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			playGameLinkedList(solution.players, solution.lastMarble*100)
+		}
+	})
+
+	b.Run("Deque", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			solution.playGame(solution.lastMarble * 100)
+		}
+	})
+}
+
+func TestGameTraceExample(t *testing.T) {
+	// The puzzle's example game, one line per marble; lines end in a space unless the current marble is last
+	expected := []string{
+		"[-] (0)",
+		"[1]  0 (1)",
+		"[2]  0 (2) 1 ",
+		"[3]  0  2  1 (3)",
+		"[4]  0 (4) 2  1  3 ",
+		"[5]  0  4  2 (5) 1  3 ",
+		"[6]  0  4  2  5  1 (6) 3 ",
+		"[7]  0  4  2  5  1  6  3 (7)",
+		"[8]  0 (8) 4  2  5  1  6  3  7 ",
+		"[9]  0  8  4 (9) 2  5  1  6  3  7 ",
+		"[1]  0  8  4  9  2(10) 5  1  6  3  7 ",
+		"[2]  0  8  4  9  2 10  5(11) 1  6  3  7 ",
+		"[3]  0  8  4  9  2 10  5 11  1(12) 6  3  7 ",
+		"[4]  0  8  4  9  2 10  5 11  1 12  6(13) 3  7 ",
+		"[5]  0  8  4  9  2 10  5 11  1 12  6 13  3(14) 7 ",
+		"[6]  0  8  4  9  2 10  5 11  1 12  6 13  3 14  7(15)",
+		"[7]  0(16) 8  4  9  2 10  5 11  1 12  6 13  3 14  7 15 ",
+		"[8]  0 16  8(17) 4  9  2 10  5 11  1 12  6 13  3 14  7 15 ",
+		"[9]  0 16  8 17  4(18) 9  2 10  5 11  1 12  6 13  3 14  7 15 ",
+		"[1]  0 16  8 17  4 18  9(19) 2 10  5 11  1 12  6 13  3 14  7 15 ",
+		"[2]  0 16  8 17  4 18  9 19  2(20)10  5 11  1 12  6 13  3 14  7 15 ",
+		"[3]  0 16  8 17  4 18  9 19  2 20 10(21) 5 11  1 12  6 13  3 14  7 15 ",
+		"[4]  0 16  8 17  4 18  9 19  2 20 10 21  5(22)11  1 12  6 13  3 14  7 15 ",
+		"[5]  0 16  8 17  4 18(19) 2 20 10 21  5 22 11  1 12  6 13  3 14  7 15 ",
+		"[6]  0 16  8 17  4 18 19  2(24)20 10 21  5 22 11  1 12  6 13  3 14  7 15 ",
+		"[7]  0 16  8 17  4 18 19  2 24 20(25)10 21  5 22 11  1 12  6 13  3 14  7 15 ",
+	}
+
+	game, err := NewGame(9, 25)
+	if err != nil {
+		t.Fatalf("NewGame() error = %v", err)
+	}
+	var trace []string
+	for line := range game.Steps() {
+		trace = append(trace, line)
+	}
+	if !slices.Equal(trace, expected) {
+		t.Errorf("trace =\n%s\nwant\n%s", strings.Join(trace, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// Only the fifth player scores, taking 23 and 9
+	if scores, want := game.Scores(), []int{0, 0, 0, 0, 32, 0, 0, 0, 0}; !slices.Equal(scores, want) {
+		t.Errorf("Scores() = %v, want %v", scores, want)
+	}
+	if game.Step() {
+		t.Errorf("Step() after the last marble = true, want false")
+	}
+}
+
+func TestGameScores(t *testing.T) {
+	game, err := NewGame(10, 1618)
+	if err != nil {
+		t.Fatalf("NewGame() error = %v", err)
+	}
+	game.Play()
+	scores := game.Scores()
+	if len(scores) != 10 {
+		t.Fatalf("len(Scores()) = %d, want 10", len(scores))
+	}
+	if game.HighScore() != 8317 || slices.Max(scores) != 8317 {
+		t.Errorf("HighScore() = %d, max of Scores() = %d, want 8317", game.HighScore(), slices.Max(scores))
+	}
+}
+
+func TestNewGameErrors(t *testing.T) {
+	for _, tt := range []struct{ players, lastMarble int }{{0, 25}, {-3, 25}, {9, -1}} {
+		if _, err := NewGame(tt.players, tt.lastMarble); err == nil {
+			t.Errorf("NewGame(%d, %d) succeeded, want an error", tt.players, tt.lastMarble)
+		}
+	}
+
+	// A game with no marbles to play is valid and just shows the starting circle
+	game, err := NewGame(1, 0)
+	if err != nil {
+		t.Fatalf("NewGame(1, 0) error = %v", err)
+	}
+	if game.Step() || game.String() != "[-] (0)" {
+		t.Errorf("NewGame(1, 0) stepped or drew %q, want no steps and \"[-] (0)\"", game.String())
+	}
+}
+
+func TestGameStringWithoutMarbleZero(t *testing.T) {
+	game, err := NewGame(9, 25)
+	if err != nil {
+		t.Fatalf("NewGame() error = %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		game.Step()
+	}
+
+	// Take marble 0 out of "[4]  0 (4) 2  1  3 ", keeping the current marble at the back
+	circle := utils.NewDeque[int](game.circle.Len())
+	for i := 0; i < game.circle.Len(); i++ {
+		if marble := game.circle.At(i); marble != 0 {
+			circle.PushBack(marble)
+		}
+	}
+	game.circle = circle
+
+	if line, want := game.String(), "[4]  1  3 (4) 2 "; line != want {
+		t.Errorf("String() = %q, want %q starting from the lowest marble", line, want)
+	}
+}
@@ -3,128 +3,214 @@
  *
  * Part 1: Simulate moving points to find when they form the smallest bounding box
  * and create a readable message. Points move with constant velocity and at some
- * moment align to display text that can be visually read.
+ * moment align to display text, which is read back with the AoC block-letter OCR.
  *
  * Part 2: Find the exact time (number of seconds) when the message appears.
- * This requires detecting the moment of minimum bounding box area.
+ * The bounding box's width plus height is convex in time, so the moment it is
+ * smallest is found by binary search rather than stepping second by second.
+ *
+ * WriteGIF animates the stars converging, for `go run ./cmd/run -day=10 -visualize=stars.gif`.
  */
 
 package day10
 
 import (
-	"regexp"
-	"strconv"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"slices"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/ocr"
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 type Point struct {
-	x, y   int
-	vx, vy int
+	X, Y   int
+	VX, VY int
 }
 
 type Solution struct {
 	points []Point
+	err    error
 }
 
 func New(input string) *Solution {
 	input = strings.ReplaceAll(strings.TrimSpace(input), "\r\n", "\n")
-	lines := strings.Split(input, "\n")
-
-	re := regexp.MustCompile(`position=<\s*(-?\d+),\s*(-?\d+)> velocity=<\s*(-?\d+),\s*(-?\d+)>`)
+	points, err := utils.ParseLines[Point](input, "position=<{x},{y}> velocity=<{vx},{vy}>")
+	return &Solution{points: points, err: err}
+}
 
-	var points []Point
-	for _, line := range lines {
-		matches := re.FindStringSubmatch(line)
-		if len(matches) == 5 {
-			x, err := strconv.Atoi(matches[1])
-			if err != nil {
-				continue // Skip invalid point lines
-			}
-			y, err := strconv.Atoi(matches[2])
-			if err != nil {
-				continue // Skip invalid point lines
-			}
-			vx, err := strconv.Atoi(matches[3])
-			if err != nil {
-				continue // Skip invalid point lines
-			}
-			vy, err := strconv.Atoi(matches[4])
-			if err != nil {
-				continue // Skip invalid point lines
-			}
-			points = append(points, Point{x, y, vx, vy})
-		}
+func (s *Solution) Part1() (string, error) {
+	message, err := s.Message()
+	if err != nil {
+		return "", err
 	}
-
-	return &Solution{points: points}
+	return ocr.Decode(message)
 }
 
-func (s *Solution) Part1() (string, error) {
+// Message returns the aligned points drawn with '#' on a '.' background.
+func (s *Solution) Message() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
 	message, _ := s.findMessage()
 	return message, nil
 }
 
 func (s *Solution) Part2() (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
 	_, time := s.findMessage()
 	return time, nil
 }
 
-// findMessage finds when points align to form the minimum bounding box and returns the message and time
+// findMessage finds when points align to form the smallest bounding box and returns the message and time
 func (s *Solution) findMessage() (string, int) {
+	if len(s.points) == 0 {
+		return "", 0
+	}
+
+	time := s.convergenceTime()
 	points := make([]Point, len(s.points))
-	copy(points, s.points)
+	for i, p := range s.points {
+		points[i] = Point{X: p.X + p.VX*time, Y: p.Y + p.VY*time, VX: p.VX, VY: p.VY}
+	}
 
-	minArea := int64(1<<63 - 1)
-	bestTime := 0
-	var bestPoints []Point
+	// Generate the visual message from the aligned points
+	message := s.visualizePoints(points)
+	return message, time
+}
 
-	// Look for the minimum bounding box area
-	for time := 0; time < 100000; time++ {
-		// Calculate bounding box
-		minX, maxX := points[0].x, points[0].x
-		minY, maxY := points[0].y, points[0].y
+// convergenceTime returns the earliest second at which the bounding box is smallest.
+// Each of the box's width and height is the spread between the largest and smallest
+// of a set of linear functions of time, which is convex, so their sum is convex too
+// and its minimum is where the step from one second to the next stops decreasing.
+func (s *Solution) convergenceTime() int {
+	decreasing := func(time int) bool {
+		return s.extent(time+1) < s.extent(time)
+	}
 
-		for _, p := range points {
-			if p.x < minX {
-				minX = p.x
-			}
-			if p.x > maxX {
-				maxX = p.x
-			}
-			if p.y < minY {
-				minY = p.y
-			}
-			if p.y > maxY {
-				maxY = p.y
-			}
+	// Double the horizon until the box has started growing, then binary search inside it
+	lo, hi := 0, 1
+	for decreasing(hi) {
+		lo, hi = hi, 2*hi
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if decreasing(mid) {
+			lo = mid + 1
+		} else {
+			hi = mid
 		}
+	}
+	return lo
+}
 
-		width := maxX - minX + 1
-		height := maxY - minY + 1
-		area := int64(width) * int64(height)
+// extent returns the bounding box's width plus height after the given number of seconds.
+func (s *Solution) extent(time int) int {
+	first := s.points[0]
+	minX, maxX := first.X+first.VX*time, first.X+first.VX*time
+	minY, maxY := first.Y+first.VY*time, first.Y+first.VY*time
+	for _, p := range s.points[1:] {
+		x, y := p.X+p.VX*time, p.Y+p.VY*time
+		minX, maxX = min(minX, x), max(maxX, x)
+		minY, maxY = min(minY, y), max(maxY, y)
+	}
+	return (maxX - minX) + (maxY - minY)
+}
 
-		if area < minArea {
-			minArea = area
-			bestTime = time
-			bestPoints = make([]Point, len(points))
-			copy(bestPoints, points)
-		}
+// GIFOptions configures WriteGIF. Zero fields take the defaults noted beside them.
+type GIFOptions struct {
+	Window int // seconds shown either side of the message, default 10
+	Width  int // image width in pixels, default 400
+	Height int // image height in pixels, default 200
+	Delay  int // time per frame in hundredths of a second, default 10
+}
 
-		// If area is getting larger again and we have a reasonable message size, we found it
-		if time > bestTime+10 && height < 20 && width < 200 {
-			break
-		}
+// gifPalette holds the background, the stars, and the stars at the moment of the message
+var gifPalette = color.Palette{
+	color.RGBA{R: 15, G: 15, B: 35, A: 255},
+	color.RGBA{R: 200, G: 200, B: 255, A: 255},
+	color.RGBA{R: 255, G: 210, B: 60, A: 255},
+}
+
+// WriteGIF writes an animation of the stars over the seconds around the message,
+// one frame per second. Each frame is scaled to fit the stars' bounding box at
+// that second, and the frame showing the message is held for longer.
+func (s *Solution) WriteGIF(w io.Writer, opts GIFOptions) error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.points) == 0 {
+		return errors.New("no points to draw")
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10
+	}
+	if opts.Width <= 0 {
+		opts.Width = 400
+	}
+	if opts.Height <= 0 {
+		opts.Height = 200
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = 10
+	}
 
-		// Move points forward one second
-		for i := range points {
-			points[i].x += points[i].vx
-			points[i].y += points[i].vy
+	converged := s.convergenceTime()
+	animation := &gif.GIF{}
+	for time := max(0, converged-opts.Window); time <= converged+opts.Window; time++ {
+		delay := opts.Delay
+		if time == converged {
+			delay *= 10
 		}
+		animation.Image = append(animation.Image, s.frame(time, time == converged, opts))
+		animation.Delay = append(animation.Delay, delay)
+	}
+	return gif.EncodeAll(w, animation)
+}
+
+// frame draws the stars after the given number of seconds, scaled so their
+// bounding box fills the image with a small margin.
+func (s *Solution) frame(time int, highlight bool, opts GIFOptions) *image.Paletted {
+	const margin = 10
+
+	xs := make([]int, len(s.points))
+	ys := make([]int, len(s.points))
+	for i, p := range s.points {
+		xs[i], ys[i] = p.X+p.VX*time, p.Y+p.VY*time
 	}
+	minX, maxX := slices.Min(xs), slices.Max(xs)
+	minY, maxY := slices.Min(ys), slices.Max(ys)
+
+	// One scale for both axes keeps the letters in proportion
+	scale := min(
+		float64(opts.Width-2*margin)/float64(maxX-minX+1),
+		float64(opts.Height-2*margin)/float64(maxY-minY+1),
+	)
+	size := max(1, int(scale))
+	offsetX := (float64(opts.Width) - scale*float64(maxX-minX+1)) / 2
+	offsetY := (float64(opts.Height) - scale*float64(maxY-minY+1)) / 2
 
-	// Generate the visual message from best points
-	message := s.visualizePoints(bestPoints)
-	return message, bestTime
+	star := uint8(1)
+	if highlight {
+		star = 2
+	}
+	img := image.NewPaletted(image.Rect(0, 0, opts.Width, opts.Height), gifPalette)
+	for i := range xs {
+		px := int(offsetX + scale*float64(xs[i]-minX))
+		py := int(offsetY + scale*float64(ys[i]-minY))
+		for dy := 0; dy < size; dy++ {
+			for dx := 0; dx < size; dx++ {
+				img.SetColorIndex(px+dx, py+dy, star)
+			}
+		}
+	}
+	return img
 }
 
 // visualizePoints creates a visual representation of the points
@@ -134,21 +220,21 @@ func (s *Solution) visualizePoints(points []Point) string {
 	}
 
 	// Find bounding box
-	minX, maxX := points[0].x, points[0].x
-	minY, maxY := points[0].y, points[0].y
+	minX, maxX := points[0].X, points[0].X
+	minY, maxY := points[0].Y, points[0].Y
 
 	for _, p := range points {
-		if p.x < minX {
-			minX = p.x
+		if p.X < minX {
+			minX = p.X
 		}
-		if p.x > maxX {
-			maxX = p.x
+		if p.X > maxX {
+			maxX = p.X
 		}
-		if p.y < minY {
-			minY = p.y
+		if p.Y < minY {
+			minY = p.Y
 		}
-		if p.y > maxY {
-			maxY = p.y
+		if p.Y > maxY {
+			maxY = p.Y
 		}
 	}
 
@@ -166,8 +252,8 @@ func (s *Solution) visualizePoints(points []Point) string {
 
 	// Mark points
 	for _, p := range points {
-		x := p.x - minX
-		y := p.y - minY
+		x := p.X - minX
+		y := p.Y - minY
 		if x >= 0 && x < width && y >= 0 && y < height {
 			grid[y][x] = '#'
 		}
@@ -1,9 +1,16 @@
 package day10
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/gif"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/ocr"
 )
 
 func readInput(t *testing.T) string {
@@ -49,10 +56,10 @@ position=<14,  7> velocity=<-2,  0>
 position=<-3,  6> velocity=< 2, -1>`
 
 	solution := New(input)
-	message, err := solution.Part1()
+	message, err := solution.Message()
 
 	if err != nil {
-		t.Errorf("Part1() error = %v", err)
+		t.Errorf("Message() error = %v", err)
 		return
 	}
 
@@ -140,8 +147,12 @@ func TestPart1(t *testing.T) {
 		return
 	}
 
-	// This will be a visual message that needs to be read
-	t.Logf("Part1() message:\n%s", result)
+	expected := "AJZNXHKE"
+	if result != expected {
+		t.Errorf("Part1() = %v, want %v", result, expected)
+	} else {
+		t.Logf("Part1() = %v", result)
+	}
 }
 
 func TestPart2(t *testing.T) {
@@ -161,6 +172,74 @@ func TestPart2(t *testing.T) {
 	}
 }
 
+func TestLateConvergence(t *testing.T) {
+	// Rewind the real input by millions of seconds so the message appears much later
+	const rewind = 5_000_000
+	original := New(readInput(t))
+	var lines []string
+	for _, p := range original.points {
+		lines = append(lines, fmt.Sprintf("position=<%d, %d> velocity=<%d, %d>", p.X-p.VX*rewind, p.Y-p.VY*rewind, p.VX, p.VY))
+	}
+	solution := New(strings.Join(lines, "\n"))
+
+	result, err := solution.Part2()
+	if err != nil {
+		t.Fatalf("Part2() error = %v", err)
+	}
+	if expected := 10905 + rewind; result != expected {
+		t.Errorf("Part2() = %v, want %v", result, expected)
+	}
+
+	message, err := solution.Part1()
+	if err != nil {
+		t.Fatalf("Part1() error = %v", err)
+	}
+	if message != "AJZNXHKE" {
+		t.Errorf("Part1() = %v, want AJZNXHKE", message)
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(readInput(t)).WriteGIF(&buf, GIFOptions{Window: 3, Width: 120, Height: 60}); err != nil {
+		t.Fatalf("WriteGIF() error = %v", err)
+	}
+
+	animation, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+
+	// Three seconds either side of the message, which is held longest
+	expectedDelays := []int{10, 10, 10, 100, 10, 10, 10}
+	if !reflect.DeepEqual(animation.Delay, expectedDelays) {
+		t.Errorf("frame delays = %v, want %v", animation.Delay, expectedDelays)
+	}
+	if bounds := animation.Image[0].Bounds(); bounds.Dx() != 120 || bounds.Dy() != 60 {
+		t.Errorf("frame size = %dx%d, want 120x60", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestMessageUnknownGlyph(t *testing.T) {
+	message, err := New(readInput(t)).Message()
+	if err != nil {
+		t.Fatalf("Message() error = %v", err)
+	}
+
+	// Light an extra cell inside the third letter, the Z starting at column 16
+	rows := split(message)
+	rows[5] = rows[5][:20] + "#" + rows[5][21:]
+
+	text, err := ocr.Decode(strings.Join(rows, "\n"))
+	var unknown *ocr.UnknownGlyphError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("ocr.Decode() error = %v, want an UnknownGlyphError", err)
+	}
+	if text != "AJ?NXHKE" || len(unknown.Columns) != 1 || unknown.Columns[0] != 16 {
+		t.Errorf("ocr.Decode() = %q, columns %v; want \"AJ?NXHKE\", columns [16]", text, unknown.Columns)
+	}
+}
+
 func split(s string) []string {
 	if s == "" {
 		return nil
@@ -7,20 +7,42 @@
  *
  * Part 2: Find the square of ANY size (1x1 to 300x300) with the largest total power.
  * Requires efficient computation using summed-area tables for optimal performance.
+ * Sizes are searched in increasing order and the search stops once an upper bound,
+ * built by tiling larger squares with the best squares already seen, shows that
+ * no larger square can beat the current best.
  */
 
 package day11
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
+// defaultGridSize is the side of the puzzle's fuel cell grid
+const defaultGridSize = 300
+
+// maxCellPower is the highest power level a single fuel cell can have
+const maxCellPower = 4
+
 type Solution struct {
 	serialNumber int
-	grid         [301][301]int // 1-indexed for convenience
-	summedArea   [301][301]int // Summed-area table for efficient range queries
+	power        *utils.SummedArea[int] // Summed-area table over the 0-indexed grid
+
+	// GridSize is the side of the square fuel cell grid
+	GridSize int
+}
+
+// Square is a square of fuel cells identified by its 1-indexed top-left corner.
+type Square struct {
+	X, Y  int
+	Size  int
+	Power int
 }
 
 func New(input string) (*Solution, error) {
@@ -30,10 +52,7 @@ func New(input string) (*Solution, error) {
 		return nil, fmt.Errorf("invalid serial number %q: %w", trimmed, err)
 	}
 
-	s := &Solution{serialNumber: serialNumber}
-	s.calculateGrid()
-	s.buildSummedAreaTable()
-	return s, nil
+	return &Solution{serialNumber: serialNumber, GridSize: defaultGridSize}, nil
 }
 
 func (s *Solution) Part1() (string, error) {
@@ -41,8 +60,8 @@ func (s *Solution) Part1() (string, error) {
 	maxX, maxY := 0, 0
 
 	// Check all possible 3x3 squares
-	for x := 1; x <= 298; x++ {
-		for y := 1; y <= 298; y++ {
+	for x := 1; x <= s.GridSize-2; x++ {
+		for y := 1; y <= s.GridSize-2; y++ {
 			power := s.getSquareSum(x, y, 3)
 			if power > maxPower {
 				maxPower = power
@@ -55,32 +74,105 @@ func (s *Solution) Part1() (string, error) {
 }
 
 func (s *Solution) Part2() (string, error) {
-	maxPower := -1000000
-	maxX, maxY, maxSize := 0, 0, 0
-
-	// Check all possible square sizes
-	for size := 1; size <= 300; size++ {
-		for x := 1; x <= 301-size; x++ {
-			for y := 1; y <= 301-size; y++ {
-				power := s.getSquareSum(x, y, size)
-				if power > maxPower {
-					maxPower = power
-					maxX, maxY, maxSize = x, y, size
+	top := s.TopSquares(1)
+	if len(top) == 0 {
+		return "", errors.New("grid has no squares")
+	}
+
+	best := top[0]
+	return strconv.Itoa(best.X) + "," + strconv.Itoa(best.Y) + "," + strconv.Itoa(best.Size), nil
+}
+
+// TopSquares returns the k squares of any size with the largest total power,
+// best first. Ties go to the smaller square, then the smaller X, then the smaller Y.
+func (s *Solution) TopSquares(k int) []Square {
+	if k < 1 {
+		return nil
+	}
+	gridSize := s.GridSize
+	table := s.table()
+
+	// Min-heap of the best squares so far, worst on top
+	top := utils.NewPQ(func(a, b Square) bool { return ranksBefore(b, a) })
+
+	// bestOfSize[n] is the most power any n×n square has.
+	// bound[m] is an upper bound on the power of any m×m square, tightened
+	// each time a new size is searched.
+	bestOfSize := make([]int, gridSize+1)
+	bound := make([]int, gridSize+1)
+	for m := range bound {
+		bound[m] = math.MaxInt
+	}
+
+	for size := 1; size <= gridSize; size++ {
+		if top.Len() == k && bound[size] <= top.Peek().Power {
+			// The bound only tightens, so check whether every larger size is ruled out too
+			done := true
+			for m := size + 1; m <= gridSize; m++ {
+				if bound[m] > top.Peek().Power {
+					done = false
+					break
+				}
+			}
+			if done {
+				break
+			}
+		}
+
+		bestOfSize[size] = math.MinInt
+		for x := 0; x+size <= gridSize; x++ {
+			for y := 0; y+size <= gridSize; y++ {
+				power := table.Sum(x, y, size, size)
+				bestOfSize[size] = max(bestOfSize[size], power)
+
+				square := Square{X: x + 1, Y: y + 1, Size: size, Power: power}
+				if top.Len() < k {
+					top.Push(square)
+				} else if ranksBefore(square, top.Peek()) {
+					top.Pop()
+					top.Push(square)
 				}
 			}
 		}
+
+		// An m×m square holds q×q disjoint size×size squares, where q = m/size,
+		// and the leftover border cells are each worth at most maxCellPower
+		for m := size + 1; m <= gridSize; m++ {
+			q := m / size
+			leftover := m*m - q*q*size*size
+			bound[m] = min(bound[m], q*q*bestOfSize[size]+leftover*maxCellPower)
+		}
 	}
 
-	return strconv.Itoa(maxX) + "," + strconv.Itoa(maxY) + "," + strconv.Itoa(maxSize), nil
+	result := make([]Square, top.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = top.Pop()
+	}
+	return result
 }
 
-// calculateGrid computes the power level for each fuel cell
-func (s *Solution) calculateGrid() {
-	for x := 1; x <= 300; x++ {
-		for y := 1; y <= 300; y++ {
-			s.grid[x][y] = s.getPowerLevel(x, y)
-		}
+// ranksBefore reports whether a is a better square than b.
+func ranksBefore(a, b Square) bool {
+	if a.Power != b.Power {
+		return a.Power > b.Power
+	}
+	if a.Size != b.Size {
+		return a.Size < b.Size
+	}
+	if a.X != b.X {
+		return a.X < b.X
 	}
+	return a.Y < b.Y
+}
+
+// table returns the summed-area table for the current grid size, building it if needed.
+func (s *Solution) table() *utils.SummedArea[int] {
+	if s.power == nil || s.power.Width() != s.GridSize {
+		s.power = utils.NewSummedArea(s.GridSize, s.GridSize, func(x, y int) int {
+			return s.getPowerLevel(x+1, y+1)
+		})
+	}
+	return s.power
 }
 
 // getPowerLevel calculates the power level for a fuel cell at (x,y)
@@ -96,25 +188,7 @@ func (s *Solution) getPowerLevel(x, y int) int {
 	return hundredsDigit - 5
 }
 
-// buildSummedAreaTable creates a summed-area table for efficient range sum queries
-func (s *Solution) buildSummedAreaTable() {
-	for x := 1; x <= 300; x++ {
-		for y := 1; y <= 300; y++ {
-			s.summedArea[x][y] = s.grid[x][y] +
-				s.summedArea[x-1][y] +
-				s.summedArea[x][y-1] -
-				s.summedArea[x-1][y-1]
-		}
-	}
-}
-
 // getSquareSum returns the sum of a square with top-left corner at (x,y) and given size
 func (s *Solution) getSquareSum(x, y, size int) int {
-	x2 := x + size - 1
-	y2 := y + size - 1
-
-	return s.summedArea[x2][y2] -
-		s.summedArea[x-1][y2] -
-		s.summedArea[x2][y-1] +
-		s.summedArea[x-1][y-1]
+	return s.table().Sum(x-1, y-1, size, size)
 }
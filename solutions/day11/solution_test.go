@@ -2,9 +2,11 @@ package day11
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"testing"
 )
@@ -138,3 +140,95 @@ func TestPart2(t *testing.T) {
 		t.Logf("Part2() = %v", result)
 	}
 }
+
+func TestTopSquaresExamples(t *testing.T) {
+	tests := []struct {
+		serial   int
+		expected Square
+	}{
+		{18, Square{X: 90, Y: 269, Size: 16, Power: 113}},
+		{42, Square{X: 232, Y: 251, Size: 12, Power: 119}},
+	}
+
+	for _, tt := range tests {
+		solution, err := New(strconv.Itoa(tt.serial))
+		if err != nil {
+			t.Fatalf("Failed to create solution: %v", err)
+		}
+
+		top := solution.TopSquares(1)
+		if len(top) != 1 || top[0] != tt.expected {
+			t.Errorf("TopSquares(1) with serial %d = %v, want [%v]", tt.serial, top, tt.expected)
+		}
+	}
+}
+
+// allSquares ranks every square in the grid without pruning.
+func allSquares(s *Solution) []Square {
+	var squares []Square
+	for size := 1; size <= s.GridSize; size++ {
+		for x := 1; x+size-1 <= s.GridSize; x++ {
+			for y := 1; y+size-1 <= s.GridSize; y++ {
+				squares = append(squares, Square{X: x, Y: y, Size: size, Power: s.getSquareSum(x, y, size)})
+			}
+		}
+	}
+	sort.Slice(squares, func(i, j int) bool { return ranksBefore(squares[i], squares[j]) })
+	return squares
+}
+
+func TestTopSquaresMatchesExhaustiveSearch(t *testing.T) {
+	for _, serial := range []int{18, 42, 8444} {
+		for _, gridSize := range []int{1, 2, 17, 60} {
+			solution, err := New(strconv.Itoa(serial))
+			if err != nil {
+				t.Fatalf("Failed to create solution: %v", err)
+			}
+			solution.GridSize = gridSize
+
+			all := allSquares(solution)
+			for _, k := range []int{1, 3, 10} {
+				want := all[:min(k, len(all))]
+				got := solution.TopSquares(k)
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Errorf("serial %d, grid %d: TopSquares(%d) = %v, want %v", serial, gridSize, k, got, want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkTopSquares compares the pruned search with trying every size on a 1000×1000 grid.
+func BenchmarkTopSquares(b *testing.B) {
+	solution, err := New("8444")
+	if err != nil {
+		b.Fatalf("Failed to create solution: %v", err)
+	}
+	solution.GridSize = 1000
+	solution.table()
+
+	for _, k := range []int{1, 10} {
+		b.Run(fmt.Sprintf("pruned/k=%d", k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				solution.TopSquares(k)
+			}
+		})
+	}
+
+	b.Run("exhaustive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			best := Square{Power: math.MinInt}
+			for size := 1; size <= solution.GridSize; size++ {
+				for x := 1; x+size-1 <= solution.GridSize; x++ {
+					for y := 1; y+size-1 <= solution.GridSize; y++ {
+						if power := solution.getSquareSum(x, y, size); power > best.Power {
+							best = Square{X: x, Y: y, Size: size, Power: power}
+						}
+					}
+				}
+			}
+		}
+	})
+}
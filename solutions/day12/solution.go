@@ -6,19 +6,35 @@
  * Sum the pot numbers containing plants after 20 generations.
  *
  * Part 2: Detect stabilization and extrapolate to 50,000,000,000 generations.
+ * Rows are bitsets trimmed to the outermost plants, so a row that has become a glider
+ * (the same shape moved along the row) shows up as two generations with equal bits
+ * and different offsets. From then on each period moves every plant by the glider's
+ * shift, so the sum grows by the shift times the number of plants per period.
+ * Rules may use any odd window width, and any RuleSet can drive an Automaton.
  */
 
 package day12
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math/bits"
 	"strconv"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
+// maxRuleWidth bounds the window of parsed rules, whose lookup table has 2^width entries.
+const maxRuleWidth = 21
+
+// maxGenerations bounds how long SumAt simulates while waiting for a glider to appear.
+const maxGenerations = 1_000_000
+
 type Solution struct {
-	initialState string
-	rules        map[string]rune
+	initial Row
+	rules   *Rules
 }
 
 func New(input string) (*Solution, error) {
@@ -28,179 +44,373 @@ func New(input string) (*Solution, error) {
 	}
 
 	// Parse initial state
-	initialLine := lines[0]
+	initialLine := strings.TrimSpace(lines[0])
 	if !strings.HasPrefix(initialLine, "initial state: ") {
 		return nil, fmt.Errorf("invalid initial state format")
 	}
-	initialState := strings.TrimPrefix(initialLine, "initial state: ")
-
-	// Parse rules
-	rules := make(map[string]rune)
-	for i := 1; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
+	initial := ParseRow(0, strings.TrimPrefix(initialLine, "initial state: "))
+
+	rules, err := ParseRules(lines[1:])
+	if err != nil {
+		// Count the initial state line in the reported position
+		var pe *utils.ParseError
+		if errors.As(err, &pe) {
+			pe.Line++
 		}
-		parts := strings.Split(line, " => ")
-		if len(parts) != 2 {
-			continue
-		}
-		pattern := parts[0]
-		result := rune(parts[1][0])
-		rules[pattern] = result
+		return nil, err
 	}
 
 	return &Solution{
-		initialState: initialState,
-		rules:        rules,
+		initial: initial,
+		rules:   rules,
 	}, nil
 }
 
 func (s *Solution) Part1() (string, error) {
-	result := s.simulate(20)
-	return strconv.Itoa(result), nil
+	return s.sumAt(20)
 }
 
 func (s *Solution) Part2() (string, error) {
-	// For 50 billion generations, we need to detect when the pattern stabilizes
-	// The sum will eventually grow by a constant amount each generation
-	result := s.simulateUntilStable(50000000000)
+	// For 50 billion generations, we need to detect when the pattern becomes a glider
+	return s.sumAt(50000000000)
+}
+
+func (s *Solution) sumAt(generation int) (string, error) {
+	a, err := s.Automaton()
+	if err != nil {
+		return "", err
+	}
+	result, err := a.SumAt(generation)
+	if err != nil {
+		return "", err
+	}
 	return strconv.Itoa(result), nil
 }
 
-func (s *Solution) simulate(generations int) int {
-	// Use map to represent pots, where key is pot number and value is whether it has a plant
-	pots := make(map[int]bool)
+// Automaton returns a fresh automaton for the puzzle's initial state and rules.
+func (s *Solution) Automaton() (*Automaton, error) {
+	return NewAutomaton(s.initial, s.rules)
+}
 
-	// Initialize pots from initial state (pot 0 is at position 0)
-	for i, char := range s.initialState {
-		if char == '#' {
-			pots[i] = true
-		}
-	}
+// RuleSet decides whether a pot holds a plant in the next generation from the window of
+// Width pots centred on it. The window's leftmost pot is its highest bit.
+type RuleSet interface {
+	Width() int
+	Grows(window uint64) bool
+}
 
-	// Simulate generations
-	for gen := 0; gen < generations; gen++ {
-		pots = s.nextGeneration(pots)
+// Rules is a RuleSet read from "..#.# => #" lines, stored as a lookup table.
+type Rules struct {
+	width int
+	grow  []bool
+}
+
+// rule is one "..#.# => #" line before validation.
+type rule struct {
+	Pattern string
+	Result  string
+}
+
+// ParseRules reads rule lines of any odd width; patterns without a rule grow nothing.
+// Blank lines are skipped, and errors are *utils.ParseError values numbering lines from 1.
+func ParseRules(lines []string) (*Rules, error) {
+	parser, err := utils.NewLineParser[rule]("{pattern} => {result}")
+	if err != nil {
+		return nil, err
 	}
 
-	// Sum pot numbers containing plants
-	sum := 0
-	for potNum, hasPlant := range pots {
-		if hasPlant {
-			sum += potNum
+	r := &Rules{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fail := func(column int, format string, args ...any) error {
+			return &utils.ParseError{Line: i + 1, Column: column, Msg: fmt.Sprintf(format, args...)}
+		}
+
+		parsed, err := parser.Parse(line)
+		if err != nil {
+			var pe *utils.ParseError
+			if errors.As(err, &pe) {
+				pe.Line = i + 1
+			}
+			return nil, err
+		}
+		pattern := parsed.Pattern
+		if r.width == 0 {
+			if len(pattern)%2 == 0 || len(pattern) > maxRuleWidth {
+				return nil, fail(1, "pattern width %d must be odd and at most %d", len(pattern), maxRuleWidth)
+			}
+			r.width = len(pattern)
+			r.grow = make([]bool, 1<<r.width)
 		}
+		if len(pattern) != r.width {
+			return nil, fail(1, "pattern width %d, earlier rules have width %d", len(pattern), r.width)
+		}
+		if parsed.Result != "#" && parsed.Result != "." {
+			return nil, fail(len(line)-len(parsed.Result)+1, "result %q must be # or .", parsed.Result)
+		}
+
+		window := 0
+		for column, c := range pattern {
+			if c != '#' && c != '.' {
+				return nil, fail(column+1, "unexpected %q in pattern", c)
+			}
+			window <<= 1
+			if c == '#' {
+				window |= 1
+			}
+		}
+		r.grow[window] = parsed.Result == "#"
+	}
+	if r.width == 0 {
+		return nil, fmt.Errorf("no rules found")
 	}
+	if r.grow[0] {
+		return nil, fmt.Errorf("an empty window grows a plant, filling the infinite row")
+	}
+	return r, nil
+}
 
-	return sum
+func (r *Rules) Width() int {
+	return r.width
 }
 
-func (s *Solution) nextGeneration(currentPots map[int]bool) map[int]bool {
-	nextPots := make(map[int]bool)
+func (r *Rules) Grows(window uint64) bool {
+	return r.grow[window]
+}
 
-	// Find the range of pots to check (expand by 2 on each side)
-	minPot, maxPot := s.getPotRange(currentPots)
-	minPot -= 2
-	maxPot += 2
+// Row is a row of pots stored as a bitset trimmed to its outermost plants:
+// bit i is pot First()+i, and the first and last bits are set unless the row is empty.
+type Row struct {
+	first  int
+	length int
+	words  []uint64
+}
 
-	// Check each pot in the expanded range
-	for pot := minPot; pot <= maxPot; pot++ {
-		pattern := s.getPattern(currentPots, pot)
-		if result, exists := s.rules[pattern]; exists && result == '#' {
-			nextPots[pot] = true
+// ParseRow reads a row drawn with '#' for plants, starting at pot first.
+func ParseRow(first int, drawing string) Row {
+	raw := make([]uint64, (len(drawing)+63)/64)
+	for i := 0; i < len(drawing); i++ {
+		if drawing[i] == '#' {
+			raw[i/64] |= 1 << (i % 64)
 		}
 	}
-
-	return nextPots
+	return trimRow(first, raw, len(drawing))
 }
 
-func (s *Solution) getPotRange(pots map[int]bool) (int, int) {
-	if len(pots) == 0 {
-		return 0, 0
+// trimRow builds a Row from the first n bits of raw, which start at pot first.
+func trimRow(first int, raw []uint64, n int) Row {
+	lo, hi := -1, -1
+	for i := 0; i < n; i++ {
+		if raw[i/64]&(1<<(i%64)) != 0 {
+			if lo < 0 {
+				lo = i
+			}
+			hi = i
+		}
+	}
+	if lo < 0 {
+		return Row{}
 	}
 
-	first := true
-	minPot, maxPot := 0, 0
-	for potNum := range pots {
-		if first {
-			minPot, maxPot = potNum, potNum
-			first = false
-			continue
-		}
-		if potNum < minPot {
-			minPot = potNum
-		}
-		if potNum > maxPot {
-			maxPot = potNum
+	r := Row{first: first + lo, length: hi - lo + 1}
+	r.words = make([]uint64, (r.length+63)/64)
+	for i := lo; i <= hi; i++ {
+		if raw[i/64]&(1<<(i%64)) != 0 {
+			r.words[(i-lo)/64] |= 1 << ((i - lo) % 64)
 		}
 	}
+	return r
+}
+
+func (r Row) bit(i int) uint64 {
+	return r.words[i/64] >> (i % 64) & 1
+}
 
-	return minPot, maxPot
+// First returns the number of the leftmost pot with a plant, or 0 for an empty row.
+func (r Row) First() int {
+	return r.first
 }
 
-func (s *Solution) getPattern(pots map[int]bool, centerPot int) string {
-	var pattern strings.Builder
+// Has reports whether the pot holds a plant.
+func (r Row) Has(pot int) bool {
+	i := pot - r.first
+	return i >= 0 && i < r.length && r.bit(i) == 1
+}
 
-	for offset := -2; offset <= 2; offset++ {
-		pot := centerPot + offset
-		if pots[pot] {
-			pattern.WriteRune('#')
-		} else {
-			pattern.WriteRune('.')
+// Plants returns the number of pots holding a plant.
+func (r Row) Plants() int {
+	count := 0
+	for _, w := range r.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Sum returns the sum of the numbers of the pots holding a plant.
+func (r Row) Sum() int {
+	sum := 0
+	for i := 0; i < r.length; i++ {
+		if r.bit(i) == 1 {
+			sum += r.first + i
 		}
 	}
+	return sum
+}
 
-	return pattern.String()
+// String draws the row from its first plant to its last.
+func (r Row) String() string {
+	var b strings.Builder
+	for i := 0; i < r.length; i++ {
+		if r.bit(i) == 1 {
+			b.WriteByte('#')
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
 }
 
-func (s *Solution) simulateUntilStable(targetGenerations int) int {
-	// Use map to represent pots
-	pots := make(map[int]bool)
+// shape identifies the row's pattern regardless of where it sits.
+func (r Row) shape() string {
+	key := make([]byte, 0, 8*len(r.words))
+	for _, w := range r.words {
+		key = binary.LittleEndian.AppendUint64(key, w)
+	}
+	return string(key)
+}
 
-	// Initialize pots from initial state
-	for i, char := range s.initialState {
-		if char == '#' {
-			pots[i] = true
+// Step returns the next generation of the row under rules.
+func (r Row) Step(rules RuleSet) Row {
+	// Only pots within half a window of a plant can grow one
+	radius := rules.Width() / 2
+	n := r.length + 2*radius
+	raw := make([]uint64, (n+63)/64)
+	mask := uint64(1)<<rules.Width() - 1
+
+	// Output pot j is centred on input pot j-radius, so its window ends at input pot j
+	window := uint64(0)
+	for j := 0; j < n; j++ {
+		window <<= 1
+		if j < r.length {
+			window |= r.bit(j)
+		}
+		if rules.Grows(window & mask) {
+			raw[j/64] |= 1 << (j % 64)
 		}
 	}
+	return trimRow(r.first-radius, raw, n)
+}
 
-	// Track sums to detect stability
-	prevSum := s.calculateSum(pots)
-	prevDiff := 0
-	stableDiffCount := 0
+// Glider describes a row that from generation Start on repeats its shape every Period
+// generations, moved Shift pots to the right (left if negative).
+type Glider struct {
+	Start  int
+	Period int
+	Shift  int
+}
 
-	for gen := 1; gen <= targetGenerations; gen++ {
-		pots = s.nextGeneration(pots)
-		currentSum := s.calculateSum(pots)
+// Automaton runs a RuleSet from an initial row and watches for the row becoming a glider.
+type Automaton struct {
+	rules      RuleSet
+	initial    Row
+	row        Row
+	generation int
+
+	// Glider detection keys each generation by its shape; firsts[g] is where generation g starts
+	shapes *utils.CycleDetector[string]
+	firsts []int
+	glider *Glider
+}
 
-		diff := currentSum - prevSum
+// NewAutomaton starts an automaton at generation 0. The rule width must be odd and fit
+// in the window, and an empty window must not grow a plant.
+func NewAutomaton(initial Row, rules RuleSet) (*Automaton, error) {
+	if width := rules.Width(); width%2 == 0 || width < 1 || width > 63 {
+		return nil, fmt.Errorf("rule width %d must be odd and between 1 and 63", width)
+	}
+	if rules.Grows(0) {
+		return nil, fmt.Errorf("an empty window grows a plant, filling the infinite row")
+	}
+	a := &Automaton{rules: rules, initial: initial, shapes: utils.NewCycleDetector[string]()}
+	a.record(initial)
+	return a, nil
+}
 
-		// Check if the difference has stabilized
-		if diff == prevDiff {
-			stableDiffCount++
-		} else {
-			stableDiffCount = 0
-		}
+// record makes row the current generation, noting a glider the first time a shape comes back.
+func (a *Automaton) record(row Row) {
+	a.row = row
+	if a.glider != nil {
+		return
+	}
+	if found, start, period := a.shapes.Add(row.shape()); found {
+		a.glider = &Glider{Start: start, Period: period, Shift: row.first - a.firsts[start]}
+		return
+	}
+	a.firsts = append(a.firsts, row.first)
+}
 
-		// If stable for several generations, we can extrapolate
-		if stableDiffCount >= 10 {
-			remainingGens := targetGenerations - gen
-			return currentSum + (diff * remainingGens)
-		}
+// Step advances one generation and returns the new row.
+func (a *Automaton) Step() Row {
+	a.generation++
+	a.record(a.row.Step(a.rules))
+	return a.row
+}
+
+// Generation returns how many generations have been run.
+func (a *Automaton) Generation() int {
+	return a.generation
+}
 
-		prevSum = currentSum
-		prevDiff = diff
+// Row returns the current row.
+func (a *Automaton) Row() Row {
+	return a.row
+}
+
+// Glider returns the glider once a shape has repeated.
+func (a *Automaton) Glider() (Glider, bool) {
+	if a.glider == nil {
+		return Glider{}, false
 	}
+	return *a.glider, true
+}
 
-	return prevSum
+// FindGlider steps until a glider appears or the automaton reaches generation limit.
+func (a *Automaton) FindGlider(limit int) (Glider, bool) {
+	for a.glider == nil && a.Generation() < limit {
+		a.Step()
+	}
+	return a.Glider()
 }
 
-func (s *Solution) calculateSum(pots map[int]bool) int {
-	sum := 0
-	for potNum, hasPlant := range pots {
-		if hasPlant {
-			sum += potNum
+// SumAt returns the sum of the pot numbers holding plants after the given number of
+// generations. It simulates a fresh automaton from the initial row, leaving this one
+// where it is, until the generation or a glider is reached. Past the glider, every
+// Period generations the same plants reappear Shift pots further along, which adds
+// Shift times the number of plants to the sum.
+func (a *Automaton) SumAt(generation int) (int, error) {
+	if generation < 0 {
+		return 0, fmt.Errorf("generation %d is negative", generation)
+	}
+	b, err := NewAutomaton(a.initial, a.rules)
+	if err != nil {
+		return 0, err
+	}
+	glider, ok := b.FindGlider(min(generation, maxGenerations))
+	if !ok {
+		if b.Generation() < generation {
+			return 0, fmt.Errorf("no glider within %d generations", maxGenerations)
 		}
+		return b.Row().Sum(), nil
 	}
-	return sum
+
+	// The glider is found at generation Start+Period, which is no later than the one asked for
+	for b.Generation() < glider.Start+glider.Period+(generation-glider.Start)%glider.Period {
+		b.Step()
+	}
+	periods := (generation - b.Generation()) / glider.Period
+	row := b.Row()
+	return row.Sum() + periods*glider.Shift*row.Plants(), nil
 }
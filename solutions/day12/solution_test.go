@@ -1,10 +1,15 @@
 package day12
 
 import (
+	"errors"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 func readInput(t *testing.T) string {
@@ -92,6 +97,19 @@ func TestPart2(t *testing.T) {
 	}
 }
 
+// simulate runs the given number of generations step by step and returns the sum of
+// pot numbers with plants. It is the reference SumAt's extrapolation is checked against.
+func simulate(s *Solution, generations int) (int, error) {
+	a, err := s.Automaton()
+	if err != nil {
+		return 0, err
+	}
+	for a.Generation() < generations {
+		a.Step()
+	}
+	return a.Row().Sum(), nil
+}
+
 func TestSimulateGenerations(t *testing.T) {
 	t.Parallel()
 	input := `initial state: #..#.#..##......###...###
@@ -117,9 +135,235 @@ func TestSimulateGenerations(t *testing.T) {
 	}
 
 	// Test only the final result that we know is correct
-	result := solution.simulate(20)
+	result, err := simulate(solution, 20)
+	if err != nil {
+		t.Fatalf("simulate(20) error = %v", err)
+	}
 	expected := 325
 	if result != expected {
 		t.Errorf("simulate(20) = %v, want %v", result, expected)
 	}
 }
+
+func TestInputGlider(t *testing.T) {
+	t.Parallel()
+	solution, err := New(readInput(t))
+	if err != nil {
+		t.Fatalf("Failed to create solution: %v", err)
+	}
+
+	// After 92 generations the plants settle and drift one pot right per generation
+	a, err := solution.Automaton()
+	if err != nil {
+		t.Fatalf("Automaton() error = %v", err)
+	}
+	glider, ok := a.FindGlider(1000)
+	if !ok {
+		t.Fatalf("FindGlider(1000) found no glider")
+	}
+	if expected := (Glider{Start: 92, Period: 1, Shift: 1}); glider != expected {
+		t.Errorf("FindGlider(1000) = %+v, want %+v", glider, expected)
+	}
+
+	// Extrapolating must agree with simulating past the point of detection
+	for _, generation := range []int{92, 150, 500} {
+		sum, err := a.SumAt(generation)
+		if err != nil {
+			t.Fatalf("SumAt(%d) error = %v", generation, err)
+		}
+		expected, err := simulate(solution, generation)
+		if err != nil {
+			t.Fatalf("simulate(%d) error = %v", generation, err)
+		}
+		if sum != expected {
+			t.Errorf("SumAt(%d) = %d, want %d", generation, sum, expected)
+		}
+	}
+
+	// SumAt runs its own automaton, so this one stays where FindGlider left it
+	if a.Generation() != 93 {
+		t.Errorf("Generation() after SumAt = %d, want 93", a.Generation())
+	}
+}
+
+func TestRow(t *testing.T) {
+	row := ParseRow(-3, "..#.#..##.")
+	if row.First() != -1 || row.String() != "#.#..##" {
+		t.Errorf("ParseRow() = %q at %d, want \"#.#..##\" at -1", row, row.First())
+	}
+	if !row.Has(1) || row.Has(0) || row.Has(-2) || row.Has(6) {
+		t.Errorf("Has() disagrees with %q at %d", row, row.First())
+	}
+	if row.Plants() != 4 || row.Sum() != -1+1+4+5 {
+		t.Errorf("Plants(), Sum() = %d, %d, want 4, 9", row.Plants(), row.Sum())
+	}
+
+	// Rows wider than a word keep their bits
+	long := ParseRow(0, "#"+strings.Repeat(".", 100)+"#")
+	if long.Plants() != 2 || !long.Has(101) || long.Sum() != 101 {
+		t.Errorf("long row has %d plants summing to %d, want 2 summing to 101", long.Plants(), long.Sum())
+	}
+}
+
+// shiftRight grows a plant wherever the pot to its left had one.
+type shiftRight struct{}
+
+func (shiftRight) Width() int               { return 3 }
+func (shiftRight) Grows(window uint64) bool { return window&0b100 != 0 }
+
+func TestCustomRuleSet(t *testing.T) {
+	a, err := NewAutomaton(ParseRow(0, "##.#"), shiftRight{})
+	if err != nil {
+		t.Fatalf("NewAutomaton() error = %v", err)
+	}
+	glider, ok := a.FindGlider(10)
+	if expected := (Glider{Start: 0, Period: 1, Shift: 1}); !ok || glider != expected {
+		t.Errorf("FindGlider(10) = %+v, %v, want %+v", glider, ok, expected)
+	}
+
+	// Three plants at 0, 1 and 3 after a billion single-pot shifts
+	sum, err := a.SumAt(1_000_000_000)
+	if err != nil {
+		t.Fatalf("SumAt() error = %v", err)
+	}
+	if expected := 4 + 3*1_000_000_000; sum != expected {
+		t.Errorf("SumAt(1e9) = %d, want %d", sum, expected)
+	}
+}
+
+func TestWideRules(t *testing.T) {
+	// With a 7-pot window, a lone plant grows another three pots to its right and dies
+	rules, err := ParseRules([]string{
+		"#...... => #",
+		"...#... => .",
+	})
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if rules.Width() != 7 {
+		t.Errorf("Width() = %d, want 7", rules.Width())
+	}
+
+	a, err := NewAutomaton(ParseRow(0, "#"), rules)
+	if err != nil {
+		t.Fatalf("NewAutomaton() error = %v", err)
+	}
+	for generation := 1; generation <= 4; generation++ {
+		row := a.Step()
+		if row.String() != "#" || row.First() != 3*generation {
+			t.Errorf("generation %d = %q at %d, want \"#\" at %d", generation, row, row.First(), 3*generation)
+		}
+	}
+	if glider, ok := a.Glider(); !ok || glider != (Glider{Start: 0, Period: 1, Shift: 3}) {
+		t.Errorf("Glider() = %+v, %v, want {Start:0 Period:1 Shift:3}", glider, ok)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"even width", []string{"#..# => #"}},
+		{"mixed widths", []string{"..#.. => #", "#.# => #"}},
+		{"empty window grows", []string{"..... => #"}},
+		{"no rules", []string{"", "  "}},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRules(tt.lines); err == nil {
+			t.Errorf("%s: ParseRules(%q) succeeded, want an error", tt.name, tt.lines)
+		}
+	}
+}
+
+func TestParseRulesPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		line   int
+		column int
+	}{
+		{"missing arrow", []string{"..#.. => #", "", "..##. -> #"}, 3, 1},
+		{"bad pattern character", []string{"..#.. => #", ".#x.. => #"}, 2, 3},
+		{"bad result", []string{"..#.. => yes"}, 1, 10},
+		{"width changes", []string{"..#.. => #", "#.# => ."}, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules(tt.lines)
+			var pe *utils.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ParseRules() error = %v, want a *utils.ParseError", err)
+			}
+			if pe.Line != tt.line || pe.Column != tt.column {
+				t.Errorf("ParseRules() error at line %d, column %d (%v), want line %d, column %d",
+					pe.Line, pe.Column, err, tt.line, tt.column)
+			}
+		})
+	}
+
+	// New counts the initial state line
+	_, err := New("initial state: #..#\n\n..#.. => #\n..#. => #")
+	var pe *utils.ParseError
+	if !errors.As(err, &pe) || pe.Line != 4 {
+		t.Errorf("New() error = %v, want one on line 4", err)
+	}
+}
+
+// tableRules is a RuleSet given by the set of windows that grow a plant.
+type tableRules struct {
+	width int
+	grow  map[uint64]bool
+}
+
+func (r tableRules) Width() int               { return r.width }
+func (r tableRules) Grows(window uint64) bool { return r.grow[window] }
+
+func TestSumAtMatchesSimulation(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	periods := make(map[int]bool)
+
+	for trial := 0; trial < 300; trial++ {
+		width := []int{3, 5}[rng.Intn(2)]
+		rules := tableRules{width: width, grow: make(map[uint64]bool)}
+		for window := uint64(1); window < 1<<width; window++ {
+			rules.grow[window] = rng.Intn(3) == 0
+		}
+		drawing := make([]byte, 1+rng.Intn(12))
+		for i := range drawing {
+			drawing[i] = ".#"[rng.Intn(2)]
+		}
+
+		initial := ParseRow(rng.Intn(11)-5, string(drawing))
+		a, err := NewAutomaton(initial, rules)
+		if err != nil {
+			t.Fatalf("NewAutomaton() error = %v", err)
+		}
+		glider, ok := a.FindGlider(100)
+		if !ok {
+			continue
+		}
+		periods[glider.Period] = true
+
+		// Simulate well past the glider and compare every generation with the shortcut
+		sim, err := NewAutomaton(initial, rules)
+		if err != nil {
+			t.Fatalf("NewAutomaton() error = %v", err)
+		}
+		for generation := 0; generation <= glider.Start+3*glider.Period+5; generation++ {
+			sum, err := a.SumAt(generation)
+			if err != nil {
+				t.Fatalf("SumAt(%d) error = %v", generation, err)
+			}
+			if want := sim.Row().Sum(); sum != want {
+				t.Fatalf("rules %v from %q: SumAt(%d) = %d, want %d (glider %+v)",
+					rules.grow, drawing, generation, sum, want, glider)
+			}
+			sim.Step()
+		}
+	}
+
+	if !periods[1] || len(periods) < 2 {
+		t.Errorf("glider periods seen = %v, want period 1 and at least one longer", periods)
+	}
+}
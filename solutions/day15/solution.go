@@ -16,11 +16,11 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
-type Point struct {
-	X, Y int
-}
+type Point = utils.Point
 
 type Unit struct {
 	Pos         Point
@@ -318,10 +318,7 @@ func (g *Game) getLivingUnitsInOrder() []*Unit {
 
 	// Sort in reading order
 	sort.Slice(living, func(i, j int) bool {
-		if living[i].Pos.Y == living[j].Pos.Y {
-			return living[i].Pos.X < living[j].Pos.X
-		}
-		return living[i].Pos.Y < living[j].Pos.Y
+		return utils.ReadingLess(living[i].Pos, living[j].Pos)
 	})
 
 	return living
@@ -366,11 +363,11 @@ func (g *Game) findTargets(unit *Unit) []*Unit {
 }
 
 func (g *Game) findAdjacentEnemies(unit *Unit) []*Unit {
-	directions := []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}} // Up, Left, Right, Down
 	var enemies []*Unit
 
-	for _, dir := range directions {
-		pos := Point{unit.Pos.X + dir.X, unit.Pos.Y + dir.Y}
+	// Up, Left, Right, Down
+	for _, dir := range utils.ReadingOrderDirs {
+		pos := unit.Pos.Add(dir)
 		if enemy := g.getUnitAt(pos); enemy != nil && enemy.Alive && enemy.Type != unit.Type {
 			enemies = append(enemies, enemy)
 		}
@@ -386,58 +383,20 @@ func (g *Game) moveUnit(unit *Unit, targets []*Unit) {
 		return // No reachable squares
 	}
 
-	// Find reachable squares and their distances
-	reachable := g.findReachableSquares(unit.Pos, inRange)
-	if len(reachable) == 0 {
-		return // No reachable squares
-	}
-
-	// Find minimum distance
-	minDist := reachable[0].dist
-	for _, r := range reachable {
-		if r.dist < minDist {
-			minDist = r.dist
-		}
-	}
-
-	// Filter to squares with minimum distance
-	var nearest []reachableSquare
-	for _, r := range reachable {
-		if r.dist == minDist {
-			nearest = append(nearest, r)
-		}
-	}
-
-	// Choose first in reading order
-	sort.Slice(nearest, func(i, j int) bool {
-		if nearest[i].pos.Y == nearest[j].pos.Y {
-			return nearest[i].pos.X < nearest[j].pos.X
-		}
-		return nearest[i].pos.Y < nearest[j].pos.Y
-	})
-
-	target := nearest[0].pos
-
-	// Find first step toward target
-	if nextStep, ok := g.findFirstStep(unit.Pos, target); ok {
+	// One reading-order BFS picks the nearest in-range square and the first step toward it
+	field := utils.BFSField([]Point{unit.Pos}, g.isOpen)
+	if nextStep, _, ok := field.StepToward(inRange); ok {
 		unit.Pos = nextStep
 	}
 }
 
-type reachableSquare struct {
-	pos  Point
-	dist int
-}
-
 func (g *Game) findInRangeSquares(targets []*Unit) []Point {
 	var inRange []Point
-	directions := []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
-
 	seen := make(map[Point]bool)
 
 	for _, target := range targets {
-		for _, dir := range directions {
-			pos := Point{target.Pos.X + dir.X, target.Pos.Y + dir.Y}
+		for _, dir := range utils.ReadingOrderDirs {
+			pos := target.Pos.Add(dir)
 			if !seen[pos] && g.isOpen(pos) {
 				inRange = append(inRange, pos)
 				seen[pos] = true
@@ -448,108 +407,11 @@ func (g *Game) findInRangeSquares(targets []*Unit) []Point {
 	return inRange
 }
 
-func (g *Game) findReachableSquares(start Point, targets []Point) []reachableSquare {
-	var reachable []reachableSquare
-
-	for _, target := range targets {
-		if dist := g.shortestPath(start, target); dist >= 0 {
-			reachable = append(reachable, reachableSquare{target, dist})
-		}
-	}
-
-	return reachable
-}
-
-func (g *Game) shortestPath(start, end Point) int {
-	if start == end {
-		return 0
-	}
-
-	queue := []Point{start}
-	distances := map[Point]int{start: 0}
-	directions := []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		for _, dir := range directions {
-			next := Point{current.X + dir.X, current.Y + dir.Y}
-
-			if _, visited := distances[next]; visited {
-				continue
-			}
-
-			if !g.isOpen(next) {
-				continue
-			}
-
-			distances[next] = distances[current] + 1
-
-			if next == end {
-				return distances[next]
-			}
-
-			queue = append(queue, next)
-		}
-	}
-
-	return -1 // Not reachable
-}
-
-func (g *Game) findFirstStep(start, end Point) (Point, bool) {
-	if start == end {
-		return Point{}, false
-	}
-
-	queue := []Point{start}
-	distances := map[Point]int{start: 0}
-	parent := map[Point]Point{start: Point{-1, -1}}
-	directions := []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		for _, dir := range directions {
-			next := Point{current.X + dir.X, current.Y + dir.Y}
-
-			if _, visited := distances[next]; visited {
-				continue
-			}
-
-			if !g.isOpen(next) {
-				continue
-			}
-
-			distances[next] = distances[current] + 1
-			parent[next] = current
-			queue = append(queue, next)
-
-			if next == end {
-				// Trace back to find first step
-				path := []Point{end}
-				curr := end
-				for parent[curr] != start {
-					curr = parent[curr]
-					path = append(path, curr)
-				}
-				return curr, true
-			}
-		}
-	}
-
-	return Point{}, false // Not reachable
-}
-
 func (g *Game) attack(unit *Unit, enemies []*Unit) {
 	// Find enemy with lowest HP (reading order tiebreaker)
 	sort.Slice(enemies, func(i, j int) bool {
 		if enemies[i].HP == enemies[j].HP {
-			if enemies[i].Pos.Y == enemies[j].Pos.Y {
-				return enemies[i].Pos.X < enemies[j].Pos.X
-			}
-			return enemies[i].Pos.Y < enemies[j].Pos.Y
+			return utils.ReadingLess(enemies[i].Pos, enemies[j].Pos)
 		}
 		return enemies[i].HP < enemies[j].HP
 	})
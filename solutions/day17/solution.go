@@ -11,9 +11,10 @@
 package day17
 
 import (
-	"regexp"
-	"strconv"
+	"fmt"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 type Point struct {
@@ -78,54 +79,50 @@ func (s *Solution) Part2() (int, error) {
 	return count, nil
 }
 
+// vein is a straight line of clay: one fixed coordinate and a range along the other axis.
+type vein struct {
+	Fixed int
+	From  int
+	To    int
+}
+
 func (s *Solution) parseInput() error {
 	lines := strings.Split(s.input, "\n")
-	
-	// Regex patterns for parsing clay coordinates
-	xPattern := regexp.MustCompile(`x=(\d+)(?:\.\.(\d+))?`)
-	yPattern := regexp.MustCompile(`y=(\d+)(?:\.\.(\d+))?`)
+
+	// Veins are either vertical (x=495, y=2..7) or horizontal (y=7, x=495..501)
+	vertical, err := utils.NewLineParser[vein]("x={fixed}, y={from}..{to}")
+	if err != nil {
+		return err
+	}
+	horizontal, err := utils.NewLineParser[vein]("y={fixed}, x={from}..{to}")
+	if err != nil {
+		return err
+	}
 
 	s.minX = 999999
 	s.maxX = -999999
 	s.minY = 999999
 	s.maxY = -999999
 
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
 			continue
 		}
 
-		xMatch := xPattern.FindStringSubmatch(line)
-		yMatch := yPattern.FindStringSubmatch(line)
-
-		if len(xMatch) < 2 || len(yMatch) < 2 {
-			continue
-		}
-
-		// Parse X range
-		x1, err := strconv.Atoi(xMatch[1])
-		if err != nil {
-			continue // Skip invalid coordinate lines
-		}
-		x2 := x1
-		if len(xMatch) > 2 && xMatch[2] != "" {
-			x2, err = strconv.Atoi(xMatch[2])
+		var x1, x2, y1, y2 int
+		if strings.HasPrefix(line, "x=") {
+			v, err := vertical.Parse(line)
 			if err != nil {
-				continue // Skip invalid coordinate lines
+				return fmt.Errorf("line %d: %w", i+1, err)
 			}
-		}
-
-		// Parse Y range
-		y1, err := strconv.Atoi(yMatch[1])
-		if err != nil {
-			continue // Skip invalid coordinate lines
-		}
-		y2 := y1
-		if len(yMatch) > 2 && yMatch[2] != "" {
-			y2, err = strconv.Atoi(yMatch[2])
+			x1, x2, y1, y2 = v.Fixed, v.Fixed, v.From, v.To
+		} else {
+			v, err := horizontal.Parse(line)
 			if err != nil {
-				continue // Skip invalid coordinate lines
+				return fmt.Errorf("line %d: %w", i+1, err)
 			}
+			x1, x2, y1, y2 = v.From, v.To, v.Fixed, v.Fixed
 		}
 
 		// Add clay tiles
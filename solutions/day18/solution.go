@@ -14,6 +14,8 @@ package day18
 
 import (
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 type Solution struct {
@@ -42,28 +44,12 @@ func (s *Solution) Part2() (int, error) {
 	s.parseInput()
 	
 	// For Part 2, we need cycle detection since 1,000,000,000 minutes is too many to simulate
-	seen := make(map[string]int)
-	minute := 0
 	target := 1000000000
-	
-	for minute < target {
-		// Convert grid to string for cycle detection
-		state := s.gridToString()
-		if prevMinute, exists := seen[state]; exists {
-			// Found a cycle! Calculate where we'll be at the target minute
-			cycleLength := minute - prevMinute
-			remaining := target - minute
-			skipCycles := remaining / cycleLength
-			minute += skipCycles * cycleLength
-		} else {
-			seen[state] = minute
-		}
-		
-		if minute < target {
-			s.simulateMinute()
-			minute++
-		}
+	step := func(current *Solution) *Solution {
+		current.simulateMinute()
+		return current
 	}
+	utils.AdvanceTo(s, target, step, (*Solution).gridToString)
 	
 	return s.calculateResourceValue(), nil
 }
@@ -13,10 +13,11 @@
 package day22
 
 import (
-	"container/heap"
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 // Region types
@@ -54,36 +55,7 @@ func New(input string) *Solution {
 
 // State for pathfinding
 type state struct {
-	x, y, tool, time int
-	index            int // for heap
-}
-
-// Priority queue implementation for Dijkstra's algorithm
-type priorityQueue []*state
-
-func (pq priorityQueue) Len() int           { return len(pq) }
-func (pq priorityQueue) Less(i, j int) bool { return pq[i].time < pq[j].time }
-func (pq priorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *priorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*state)
-	item.index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *priorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	*pq = old[0 : n-1]
-	return item
+	x, y, tool int
 }
 
 func (s *Solution) Part1() (int, error) {
@@ -189,37 +161,12 @@ func (s *Solution) Part2() (int, error) {
 		return false
 	}
 
-	// Dijkstra's algorithm with state = (x, y, tool)
-	pq := make(priorityQueue, 0)
-	heap.Init(&pq)
-
-	// Start at 0,0 with torch equipped
-	start := &state{x: 0, y: 0, tool: torch, time: 0}
-	heap.Push(&pq, start)
-
-	// Track visited states and best-known times
-	visited := make(map[string]bool)
-	best := make(map[string]int)
-
 	// Directions: up, down, left, right
 	dx := []int{0, 0, -1, 1}
 	dy := []int{-1, 1, 0, 0}
 
-	// Search for shortest path
-	for pq.Len() > 0 {
-		current := heap.Pop(&pq).(*state)
-
-		// Create state key
-		stateKey := fmt.Sprintf("%d,%d,%d", current.x, current.y, current.tool)
-		if visited[stateKey] {
-			continue
-		}
-		visited[stateKey] = true
-
-		// Check if we reached the target with torch equipped
-		if current.x == targetX && current.y == targetY && current.tool == torch {
-			return current.time, nil
-		}
+	getNeighbors := func(current state) []utils.Edge[state] {
+		edges := make([]utils.Edge[state], 0, 5)
 
 		// Try moving to adjacent regions
 		for i := 0; i < 4; i++ {
@@ -235,16 +182,8 @@ func (s *Solution) Part2() (int, error) {
 				continue
 			}
 
-			nextRegionType := getRegionType(nx, ny)
-			if isValidTool(nextRegionType, current.tool) {
-				nextStateKey := fmt.Sprintf("%d,%d,%d", nx, ny, current.tool)
-				if !visited[nextStateKey] {
-					nt := current.time + moveCost
-					if bt, ok := best[nextStateKey]; !ok || nt < bt {
-						best[nextStateKey] = nt
-						heap.Push(&pq, &state{x: nx, y: ny, tool: current.tool, time: nt})
-					}
-				}
+			if isValidTool(getRegionType(nx, ny), current.tool) {
+				edges = append(edges, utils.Edge[state]{To: state{nx, ny, current.tool}, Cost: moveCost})
 			}
 		}
 
@@ -252,15 +191,54 @@ func (s *Solution) Part2() (int, error) {
 		currentRegionType := getRegionType(current.x, current.y)
 		for newTool := neither; newTool <= gear; newTool++ {
 			if newTool != current.tool && isValidTool(currentRegionType, newTool) {
-				nextStateKey := fmt.Sprintf("%d,%d,%d", current.x, current.y, newTool)
-				if !visited[nextStateKey] {
-					nt := current.time + switchCost
-					if bt, ok := best[nextStateKey]; !ok || nt < bt {
-						best[nextStateKey] = nt
-						heap.Push(&pq, &state{x: current.x, y: current.y, tool: newTool, time: nt})
-					}
-				}
+				edges = append(edges, utils.Edge[state]{To: state{current.x, current.y, newTool}, Cost: switchCost})
+			}
+		}
+
+		return edges
+	}
+
+	// Reach the target with torch equipped
+	isTarget := func(current state) bool {
+		return current.x == targetX && current.y == targetY && current.tool == torch
+	}
+
+	// Manhattan distance never overestimates, plus a switch if the torch isn't equipped.
+	// It is also consistent: a move changes it by at most moveCost and a switch by at most
+	// switchCost, so the estimates popped by A* never decrease.
+	heuristic := func(current state) int {
+		h := utils.Abs(current.x-targetX) + utils.Abs(current.y-targetY)
+		if current.tool != torch {
+			h += switchCost
+		}
+		return h
+	}
+
+	// Start at 0,0 with torch equipped. The estimates are small, non-decreasing
+	// integers, so a bucket per estimate replaces the binary heap.
+	start := state{x: 0, y: 0, tool: torch}
+	dist := map[state]int{start: 0}
+	queue := utils.NewBucketQueue[state]()
+	queue.Push(start, heuristic(start))
+
+	for queue.Len() > 0 {
+		current, estimate := queue.Pop()
+		cost := dist[current]
+		if estimate > cost+heuristic(current) {
+			// A cheaper way here was queued after this one
+			continue
+		}
+		if isTarget(current) {
+			return cost, nil
+		}
+
+		for _, edge := range getNeighbors(current) {
+			newDist := cost + edge.Cost
+			if d, ok := dist[edge.To]; ok && newDist >= d {
+				continue
 			}
+			dist[edge.To] = newDist
+			queue.Push(edge.To, newDist+heuristic(edge.To))
 		}
 	}
 
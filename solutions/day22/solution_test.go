@@ -75,4 +75,18 @@ func TestPart2(t *testing.T) {
 	if result != expected {
 		t.Errorf("Part2() = %v, want %v", result, expected)
 	}
-}
\ No newline at end of file
+}
+
+func BenchmarkPart2(b *testing.B) {
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		b.Fatalf("Failed to read input: %v", err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		solution := New(string(input))
+		if _, err := solution.Part2(); err != nil {
+			b.Fatalf("Part2() error = %v", err)
+		}
+	}
+}
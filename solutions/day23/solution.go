@@ -1,69 +1,43 @@
 package day23
 
 import (
-	"container/heap"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/shnako/advent-of-code-2018-ai/internal/spatial"
 	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 type Nanobot struct {
-	x, y, z int
-	radius  int
+	X, Y, Z int
+	Radius  int `parse:"r"`
 }
 
-func ParseInput(input string) []Nanobot {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
-	nanobots := make([]Nanobot, 0, len(lines))
-	
-	re := regexp.MustCompile(`pos=<(-?\d+),(-?\d+),(-?\d+)>, r=(\d+)`)
-	
-	for _, line := range lines {
-		matches := re.FindStringSubmatch(line)
-		if len(matches) == 5 {
-			x, err := strconv.Atoi(matches[1])
-			if err != nil {
-				continue // Skip invalid nanobot lines
-			}
-			y, err := strconv.Atoi(matches[2])
-			if err != nil {
-				continue // Skip invalid nanobot lines
-			}
-			z, err := strconv.Atoi(matches[3])
-			if err != nil {
-				continue // Skip invalid nanobot lines
-			}
-			r, err := strconv.Atoi(matches[4])
-			if err != nil {
-				continue // Skip invalid nanobot lines
-			}
-			nanobots = append(nanobots, Nanobot{x, y, z, r})
-		}
-	}
-	
-	return nanobots
+// ParseInput parses lines of the form pos=<x,y,z>, r=radius.
+func ParseInput(input string) ([]Nanobot, error) {
+	return utils.ParseLines[Nanobot](strings.TrimSpace(input), "pos=<{x},{y},{z}>, r={r}")
 }
 
-
 func manhattanDistance(n1, n2 Nanobot) int {
-	return utils.Abs(n1.x-n2.x) + utils.Abs(n1.y-n2.y) + utils.Abs(n1.z-n2.z)
+	return utils.Abs(n1.X-n2.X) + utils.Abs(n1.Y-n2.Y) + utils.Abs(n1.Z-n2.Z)
 }
 
 func Part1(input string) (string, error) {
-	nanobots := ParseInput(input)
+	nanobots, err := ParseInput(input)
+	if err != nil {
+		return "", err
+	}
 	
 	if len(nanobots) == 0 {
 		return "", fmt.Errorf("no nanobots found")
 	}
 	
 	strongestIdx := 0
-	maxRadius := nanobots[0].radius
+	maxRadius := nanobots[0].Radius
 	for i := 1; i < len(nanobots); i++ {
-		if nanobots[i].radius > maxRadius {
-			maxRadius = nanobots[i].radius
+		if nanobots[i].Radius > maxRadius {
+			maxRadius = nanobots[i].Radius
 			strongestIdx = i
 		}
 	}
@@ -71,7 +45,7 @@ func Part1(input string) (string, error) {
 	strongest := nanobots[strongestIdx]
 	count := 0
 	for _, bot := range nanobots {
-		if manhattanDistance(strongest, bot) <= strongest.radius {
+		if manhattanDistance(strongest, bot) <= strongest.Radius {
 			count++
 		}
 	}
@@ -87,97 +61,56 @@ type Box struct {
 }
 
 
-func distanceToBox(bot Nanobot, box Box) int {
-	dist := 0
-	
-	if bot.x < box.x {
-		dist += box.x - bot.x
-	} else if bot.x >= box.x+box.size {
-		dist += bot.x - (box.x + box.size - 1)
-	}
-	
-	if bot.y < box.y {
-		dist += box.y - bot.y
-	} else if bot.y >= box.y+box.size {
-		dist += bot.y - (box.y + box.size - 1)
+// bounds returns the inclusive extent of the box.
+func (b Box) bounds() spatial.Box {
+	return spatial.Box{
+		Min: spatial.Point{b.x, b.y, b.z},
+		Max: spatial.Point{b.x + b.size - 1, b.y + b.size - 1, b.z + b.size - 1},
 	}
-	
-	if bot.z < box.z {
-		dist += box.z - bot.z
-	} else if bot.z >= box.z+box.size {
-		dist += bot.z - (box.z + box.size - 1)
-	}
-	
-	return dist
 }
 
-func countBotsInRangeOfBox(box Box, nanobots []Nanobot) int {
-	count := 0
-	for _, bot := range nanobots {
-		if distanceToBox(bot, box) <= bot.radius {
-			count++
-		}
+// newBotIndex indexes the nanobots' ranges so boxes can be counted without scanning every bot.
+func newBotIndex(nanobots []Nanobot) *spatial.BallTree {
+	balls := make([]spatial.Ball, len(nanobots))
+	for i, bot := range nanobots {
+		balls[i] = spatial.Ball{Center: spatial.Point{bot.X, bot.Y, bot.Z}, Radius: bot.Radius}
 	}
-	return count
+	return spatial.NewBallTree(balls, 3)
 }
 
 type Item struct {
 	box      Box
 	count    int
 	distance int
-	index    int
 }
 
-type PriorityQueue []*Item
-
-func (pq PriorityQueue) Len() int { return len(pq) }
-
-func (pq PriorityQueue) Less(i, j int) bool {
-	if pq[i].count != pq[j].count {
-		return pq[i].count > pq[j].count
+// itemLess orders boxes by most bots in range, then closest to origin, then smallest.
+func itemLess(a, b Item) bool {
+	if a.count != b.count {
+		return a.count > b.count
 	}
-	if pq[i].distance != pq[j].distance {
-		return pq[i].distance < pq[j].distance
+	if a.distance != b.distance {
+		return a.distance < b.distance
 	}
-	return pq[i].box.size < pq[j].box.size
-}
-
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*Item)
-	item.index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	*pq = old[0 : n-1]
-	return item
+	return a.box.size < b.box.size
 }
 
 func Part2(input string) (string, error) {
-	nanobots := ParseInput(input)
+	nanobots, err := ParseInput(input)
+	if err != nil {
+		return "", err
+	}
 	
 	if len(nanobots) == 0 {
 		return "", fmt.Errorf("no nanobots found")
 	}
 	
-	minCoord := nanobots[0].x
-	maxCoord := nanobots[0].x
+	minCoord := nanobots[0].X
+	maxCoord := nanobots[0].X
 	
 	for _, bot := range nanobots {
-		minCoord = utils.Min(minCoord, utils.Min(bot.x, utils.Min(bot.y, bot.z)))
-		maxCoord = utils.Max(maxCoord, utils.Max(bot.x, utils.Max(bot.y, bot.z)))
+		minCoord = utils.Min(minCoord, utils.Min(bot.X, utils.Min(bot.Y, bot.Z)))
+		maxCoord = utils.Max(maxCoord, utils.Max(bot.X, utils.Max(bot.Y, bot.Z)))
 	}
 	
 	boxSize := 1
@@ -185,16 +118,16 @@ func Part2(input string) (string, error) {
 		boxSize *= 2
 	}
 	
-	pq := make(PriorityQueue, 0)
-	heap.Init(&pq)
+	index := newBotIndex(nanobots)
+	pq := utils.NewPQ(itemLess)
 	
 	initialBox := Box{minCoord, minCoord, minCoord, boxSize}
-	initialCount := countBotsInRangeOfBox(initialBox, nanobots)
+	initialCount := index.CountIntersecting(initialBox.bounds())
 	initialDist := utils.Abs(minCoord) + utils.Abs(minCoord) + utils.Abs(minCoord)
-	heap.Push(&pq, &Item{initialBox, initialCount, initialDist, 0})
+	pq.Push(Item{initialBox, initialCount, initialDist})
 	
 	for pq.Len() > 0 {
-		item := heap.Pop(&pq).(*Item)
+		item := pq.Pop()
 		
 		if item.box.size == 1 {
 			return strconv.Itoa(item.distance), nil
@@ -215,10 +148,10 @@ func Part2(input string) (string, error) {
 						newSize,
 					}
 					
-					count := countBotsInRangeOfBox(newBox, nanobots)
+					count := index.CountIntersecting(newBox.bounds())
 					dist := utils.Abs(newBox.x) + utils.Abs(newBox.y) + utils.Abs(newBox.z)
 					
-					heap.Push(&pq, &Item{newBox, count, dist, 0})
+					pq.Push(Item{newBox, count, dist})
 				}
 			}
 		}
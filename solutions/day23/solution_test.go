@@ -1,8 +1,13 @@
 package day23
 
 import (
+	"fmt"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/spatial"
 )
 
 func TestPart1Example(t *testing.T) {
@@ -78,4 +83,62 @@ func TestPart2(t *testing.T) {
 	if result != expected {
 		t.Errorf("Part2() = %s, want %s", result, expected)
 	}
-}
\ No newline at end of file
+}
+
+// syntheticInput generates n nanobots spread like the real input, with a fixed seed.
+func syntheticInput(n int) string {
+	rng := rand.New(rand.NewSource(23))
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "pos=<%d,%d,%d>, r=%d\n",
+			rng.Intn(200_000_000)-100_000_000,
+			rng.Intn(200_000_000)-100_000_000,
+			rng.Intn(200_000_000)-100_000_000,
+			rng.Intn(50_000_000)+50_000_000)
+	}
+	return sb.String()
+}
+
+func BenchmarkPart2Synthetic(b *testing.B) {
+	input := syntheticInput(2_000)
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(input); err != nil {
+			b.Fatalf("Part2() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkCountBotsInBox compares scanning every bot with querying the ball tree.
+func BenchmarkCountBotsInBox(b *testing.B) {
+	nanobots, err := ParseInput(syntheticInput(10_000))
+	if err != nil {
+		b.Fatalf("ParseInput() error = %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	boxes := make([]Box, 1000)
+	for i := range boxes {
+		boxes[i] = Box{rng.Intn(200_000_000) - 100_000_000, rng.Intn(200_000_000) - 100_000_000, rng.Intn(200_000_000) - 100_000_000, 1 << rng.Intn(24)}
+	}
+
+	b.Run("Scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, box := range boxes {
+				count := 0
+				for _, bot := range nanobots {
+					if spatial.DistanceToBox(spatial.Point{bot.X, bot.Y, bot.Z}, box.bounds()) <= bot.Radius {
+						count++
+					}
+				}
+			}
+		}
+	})
+
+	b.Run("BallTree", func(b *testing.B) {
+		index := newBotIndex(nanobots)
+		for i := 0; i < b.N; i++ {
+			for _, box := range boxes {
+				index.CountIntersecting(box.bounds())
+			}
+		}
+	})
+}
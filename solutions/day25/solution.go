@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/shnako/advent-of-code-2018-ai/internal/spatial"
 	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
@@ -12,62 +13,13 @@ type Point struct {
 	x, y, z, t int
 }
 
-
-func manhattanDistance(p1, p2 Point) int {
-	return utils.Abs(p1.x-p2.x) + utils.Abs(p1.y-p2.y) + utils.Abs(p1.z-p2.z) + utils.Abs(p1.t-p2.t)
-}
-
-type UnionFind struct {
-	parent map[int]int
-	rank   map[int]int
-}
-
-func NewUnionFind() *UnionFind {
-	return &UnionFind{
-		parent: make(map[int]int),
-		rank:   make(map[int]int),
-	}
-}
-
-func (uf *UnionFind) makeSet(x int) {
-	if _, exists := uf.parent[x]; !exists {
-		uf.parent[x] = x
-		uf.rank[x] = 0
-	}
-}
-
-func (uf *UnionFind) find(x int) int {
-	if uf.parent[x] != x {
-		uf.parent[x] = uf.find(uf.parent[x]) // Path compression
+// toSpatial converts points to spatial coordinates for indexing.
+func toSpatial(points []Point) []spatial.Point {
+	coords := make([]spatial.Point, len(points))
+	for i, p := range points {
+		coords[i] = spatial.Point{p.x, p.y, p.z, p.t}
 	}
-	return uf.parent[x]
-}
-
-func (uf *UnionFind) union(x, y int) {
-	rootX := uf.find(x)
-	rootY := uf.find(y)
-	
-	if rootX == rootY {
-		return
-	}
-	
-	// Union by rank
-	if uf.rank[rootX] < uf.rank[rootY] {
-		uf.parent[rootX] = rootY
-	} else if uf.rank[rootX] > uf.rank[rootY] {
-		uf.parent[rootY] = rootX
-	} else {
-		uf.parent[rootY] = rootX
-		uf.rank[rootX]++
-	}
-}
-
-func (uf *UnionFind) countSets() int {
-	roots := make(map[int]bool)
-	for node := range uf.parent {
-		roots[uf.find(node)] = true
-	}
-	return len(roots)
+	return coords
 }
 
 func parseInput(input string) []Point {
@@ -106,25 +58,31 @@ func parseInput(input string) []Point {
 func Part1(input string) string {
 	points := parseInput(input)
 	
-	// Create Union-Find structure
-	uf := NewUnionFind()
+	// Count the number of constellations
+	return fmt.Sprintf("%d", countConstellations(points))
+}
+
+// countConstellations groups points within Manhattan distance 3 of each other.
+func countConstellations(points []Point) int {
+	coords := toSpatial(points)
 	
-	// Initialize each point as its own set
-	for i := range points {
-		uf.makeSet(i)
-	}
+	// Bucket points into cells the size of the link distance so each point
+	// only checks nearby cells instead of every other point
+	grid := spatial.NewGrid(coords, 4, 3)
+	
+	// Each point starts as its own constellation
+	uf := utils.NewDenseUnionFind(len(points))
 	
 	// Connect points that are within Manhattan distance of 3
-	for i := 0; i < len(points); i++ {
-		for j := i + 1; j < len(points); j++ {
-			if manhattanDistance(points[i], points[j]) <= 3 {
-				uf.union(i, j)
+	for i, p := range coords {
+		grid.VisitWithinRadius(p, 3, func(j int) {
+			if j > i {
+				uf.Union(i, j)
 			}
-		}
+		})
 	}
 	
-	// Count the number of constellations
-	return fmt.Sprintf("%d", uf.countSets())
+	return uf.CountSets()
 }
 
 func Part2(input string) string {
@@ -1,9 +1,13 @@
 package day25
 
 import (
+	"math/rand"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/shnako/advent-of-code-2018-ai/internal/spatial"
+	"github.com/shnako/advent-of-code-2018-ai/internal/utils"
 )
 
 func TestPart1Example1(t *testing.T) {
@@ -101,4 +105,78 @@ func TestPart2(t *testing.T) {
 	if result != expected {
 		t.Errorf("Part2 = %s; want %s", result, expected)
 	}
-}
\ No newline at end of file
+}
+
+// BenchmarkConstellations compares the map-backed and slice-backed union-find on the real input.
+// Neighbouring pairs are found up front so only the union-find work is measured.
+func BenchmarkConstellations(b *testing.B) {
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		b.Fatalf("Failed to read input: %v", err)
+	}
+	points := parseInput(string(input))
+
+	coords := toSpatial(points)
+	var pairs [][2]int
+	for i := 0; i < len(coords); i++ {
+		for j := i + 1; j < len(coords); j++ {
+			if spatial.Manhattan(coords[i], coords[j]) <= 3 {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+
+	b.Run("UnionFind", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			uf := utils.NewUnionFind[int]()
+			for i := range points {
+				uf.MakeSet(i)
+			}
+			for _, pair := range pairs {
+				uf.Union(pair[0], pair[1])
+			}
+			uf.CountSets()
+		}
+	})
+
+	b.Run("DenseUnionFind", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			uf := utils.NewDenseUnionFind(len(points))
+			for _, pair := range pairs {
+				uf.Union(pair[0], pair[1])
+			}
+			uf.CountSets()
+		}
+	})
+}
+
+// BenchmarkCountConstellations compares checking every pair with the grid index
+// on an enlarged synthetic input of 20,000 points.
+func BenchmarkCountConstellations(b *testing.B) {
+	rng := rand.New(rand.NewSource(25))
+	points := make([]Point, 20_000)
+	for i := range points {
+		points[i] = Point{rng.Intn(61) - 30, rng.Intn(61) - 30, rng.Intn(61) - 30, rng.Intn(61) - 30}
+	}
+
+	b.Run("PairScan", func(b *testing.B) {
+		coords := toSpatial(points)
+		for n := 0; n < b.N; n++ {
+			uf := utils.NewDenseUnionFind(len(coords))
+			for i := 0; i < len(coords); i++ {
+				for j := i + 1; j < len(coords); j++ {
+					if spatial.Manhattan(coords[i], coords[j]) <= 3 {
+						uf.Union(i, j)
+					}
+				}
+			}
+			uf.CountSets()
+		}
+	})
+
+	b.Run("Grid", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			countConstellations(points)
+		}
+	})
+}